@@ -0,0 +1,66 @@
+// Command exporter dumps the whole catalog from the books database.
+//
+//	go run ./cmd/exporter -format xml -o books.xml
+//
+// Books are streamed as they're read, the catalog is never held in memory.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/catalog"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/clients"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/repositories"
+)
+
+func main() {
+	format := flag.String("format", "", "export format, one of "+strings.Join(catalog.ExportFormats, ", ")+", guessed from the output extension and csv by default")
+	output := flag.String("o", "", "file to write the export to, stdout by default")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *format == "" && *output != "" {
+		*format = catalog.FormatOf(*output)
+	}
+	if *format == "" {
+		*format = catalog.FormatCSV
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+
+	db, err := clients.ConnectDB(context.Background(), cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := catalog.Export(context.Background(), repositories.NewBooksRepo(db, cfg.MySQL.QueryTimeouts), buffered, *format); err != nil {
+		log.Fatal(err)
+	}
+	if err := buffered.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
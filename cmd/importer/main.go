@@ -0,0 +1,117 @@
+// Command importer loads a CSV or JSON Lines catalog file into the books
+// database.
+//
+//	go run ./cmd/importer -actor-id 1 [-dry-run] [-status draft] catalog.csv
+//
+// Progress is checkpointed in the database along with each batch, running the
+// same command again after a failure resumes where the last run left off.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/catalog"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/clients"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/repositories"
+)
+
+func main() {
+	format := flag.String("format", "", "catalog format, csv or jsonl, guessed from the file extension by default")
+	dryRun := flag.Bool("dry-run", false, "validate the file and report what would be imported without writing anything")
+	actorID := flag.Int64("actor-id", 0, "id of the user the books are imported on behalf of")
+	status := flag.String("status", domain.StatusPendingReview, "status the books are created in")
+	batchSize := flag.Int("batch-size", catalog.DefaultBatchSize, "books saved per transaction")
+	checkpoint := flag.String("checkpoint", "", "name the import's progress is tracked under in the database, the file's absolute path by default")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if flag.NArg() != 1 || *actorID <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	if *format == "" {
+		*format = catalog.FormatOf(path)
+	}
+	if *checkpoint == "" {
+		if *checkpoint, err = filepath.Abs(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := catalog.NewReader(file, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := clients.ConnectDB(context.Background(), cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	importer := catalog.Importer{
+		Repo:       repositories.NewBooksRepo(db, cfg.MySQL.QueryTimeouts),
+		ActorID:    *actorID,
+		Status:     *status,
+		BatchSize:  *batchSize,
+		DryRun:     *dryRun,
+		Checkpoint: *checkpoint,
+	}
+
+	report, err := importer.Run(context.Background(), reader)
+	printReport(report, *dryRun)
+	if err != nil {
+		log.Printf("import stopped: %s", err)
+		if !*dryRun {
+			log.Printf("fix the file and run the same command again to resume after line %d", report.LastLine)
+		}
+		os.Exit(1)
+	}
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printReport(report *catalog.Report, dryRun bool) {
+	if report == nil {
+		return
+	}
+
+	verb := "imported"
+	if dryRun {
+		verb = "valid"
+	}
+	fmt.Printf("records read: %d, skipped on resume: %d, %s: %d, invalid: %d\n",
+		report.Read, report.Skipped, verb, report.Imported, len(report.Errors))
+
+	for _, name := range report.CreatedPublishers {
+		fmt.Printf("new publisher: %s\n", name)
+	}
+	for _, name := range report.CreatedAuthors {
+		fmt.Printf("new author: %s\n", name)
+	}
+	for _, lineErr := range report.Errors {
+		fmt.Println(lineErr)
+	}
+}
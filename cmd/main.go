@@ -2,43 +2,133 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/clients"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
 	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/http/rest"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/logging"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/metrics"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/repositories"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/rpc"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/tracing"
 	"github.com/FacuBar/bookstore_utils-go/auth"
-	"github.com/joho/godotenv"
 )
 
+// traceFlushTimeout bounds exporting the spans left once the servers stopped
+const traceFlushTimeout = 5 * time.Second
+
 func main() {
-	err := godotenv.Load()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger := logging.New(cfg.Log)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		panic("Error loading .env file")
+		logger.Fatal().Err(err).Msg("starting up")
 	}
 
-	db := clients.ConnectDB()
+	db, err := clients.ConnectDB(context.Background(), cfg.MySQL)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("starting up")
+	}
+
+	if cfg.Migrate.OnStart {
+		migrator, err := newMigrator(db, cfg.Migrate)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("starting up")
+		}
+		applied, err := migrator.Up(context.Background())
+		for _, m := range applied {
+			logger.Info().Stringer("migration", m).Msg("migration applied")
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("migrating")
+		}
+	}
 
-	oauthClient, err := auth.NewClient(os.Getenv("GRPC_ADDRESS"))
+	authConn, err := clients.DialAuth(cfg.OAuth)
 	if err != nil {
-		panic("error initializing grpc client")
+		logger.Fatal().Err(err).Msg("starting up")
 	}
+	oauthClient := &auth.Client{C: auth.NewOauthServiceClient(authConn)}
 
-	server := rest.NewServer(&http.Server{Addr: os.Getenv("PORT")}, db, oauthClient)
+	m := metrics.New()
+	m.WatchDB(db, cfg.MySQL.Database)
+	booksRepo := m.Repository(tracing.Repository(repositories.NewBooksRepo(db, cfg.MySQL.QueryTimeouts)))
 
-	go server.Start()
+	server := rest.NewServer(cfg.HTTP, db, booksRepo, oauthClient, authConn, m, logger)
+
+	grpcServer := rpc.NewServer(cfg.GRPC.Addr, booksRepo, oauthClient)
+
+	errs := make(chan error, 2)
+	go func() { errs <- server.Start() }()
+	go func() { errs <- grpcServer.Start() }()
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
-	<-quit
-	log.Println("Shutdown server ...")
+	exitCode := 0
+	select {
+	case sig := <-quit:
+		logger.Info().Str("signal", sig.String()).Msg("shutting down")
+	case err := <-errs:
+		logger.Error().Err(err).Msg("shutting down")
+		exitCode = 1
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// the drain delay comes on top of the time in-flight requests get
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.DrainDelay+cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 
-	server.Stop(ctx)
+	// both APIs drain first, the connections their requests use are closed
+	// once no request is left
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := server.Stop(ctx); err != nil {
+			logger.Error().Err(err).Msg("http shutdown")
+			exitCode = 1
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		grpcServer.Stop(ctx)
+	}()
+	wg.Wait()
+
+	if err := db.Close(); err != nil {
+		logger.Error().Err(err).Msg("closing mysql")
+	}
+	if err := authConn.Close(); err != nil {
+		logger.Error().Err(err).Msg("closing users service connection")
+	}
+
+	// spans of the last requests are still waiting in the batch, they get
+	// their own time as draining may have used all of ctx's
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), traceFlushTimeout)
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Error().Err(err).Msg("flushing traces")
+	}
+	cancelFlush()
+
+	logger.Info().Msg("server exited")
+	cancel()
+	os.Exit(exitCode)
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/FacuBar/bookstore_books-api/migration"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/clients"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/migrations"
+)
+
+const migrateUsage = `usage: %s migrate [flags] command
+
+commands:
+  up             apply the pending migrations
+  down [N]       revert the last N migrations applied, 1 by default
+  status         print the version the schema is at and the migrations pending
+  force VERSION  record the schema as being at VERSION, 0 for none, without
+                 running anything, once a failed migration was fixed by hand
+
+flags:
+`
+
+// runMigrate runs the migrate subcommand, taking the same flags as the
+// service for reaching MySQL
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), migrateUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	command := fs.Args()
+	if !validCommand(command) {
+		return fmt.Errorf("unknown migrate command %q, see %s migrate -h", strings.Join(command, " "), os.Args[0])
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := clients.ConnectDB(ctx, cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(db, cfg.Migrate)
+	if err != nil {
+		return err
+	}
+
+	switch command[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Println("applied", m)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no change")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(command) == 2 {
+			if steps, err = strconv.Atoi(command[1]); err != nil || steps <= 0 {
+				return errors.New("down takes how many migrations to revert, a number from 1")
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Println("reverted", m)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no change")
+		}
+		return err
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		dirty := ""
+		if status.Dirty {
+			dirty = " (dirty)"
+		}
+		fmt.Printf("version %d%s, %d pending\n", status.Version, dirty, len(status.Pending))
+		for _, m := range status.Pending {
+			fmt.Println("pending", m)
+		}
+		return nil
+
+	default:
+		version, err := strconv.ParseUint(command[1], 10, 64)
+		if err != nil {
+			return errors.New("force takes the version to record, a number from 0")
+		}
+		return migrator.Force(ctx, version)
+	}
+}
+
+// validCommand tells whether command is one of the subcommands, with the
+// arguments it takes
+func validCommand(command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+	switch command[0] {
+	case "up", "status":
+		return len(command) == 1
+	case "down":
+		return len(command) <= 2
+	case "force":
+		return len(command) == 2
+	}
+	return false
+}
+
+func newMigrator(db *sql.DB, cfg config.Migrate) (*migrations.Migrator, error) {
+	embedded, err := migrations.Load(migration.Files)
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(db, embedded, cfg.LockTimeout), nil
+}
@@ -0,0 +1,90 @@
+// Command onix ingests an ONIX 3.0 message into the books database, creating
+// or updating its products by ISBN.
+//
+//	go run ./cmd/onix -actor-id 1 [-dry-run] [-currency EUR] feed.xml
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/clients"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/onix"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/repositories"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "map and resolve every product and report what would be done without writing anything")
+	actorID := flag.Int64("actor-id", 0, "id of the user the products are ingested on behalf of")
+	currency := flag.String("currency", onix.DefaultCurrency, "currency prices are taken in")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if flag.NArg() != 1 || *actorID <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	db, err := clients.ConnectDB(context.Background(), cfg.MySQL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ingester := onix.Ingester{
+		Repo:     repositories.NewBooksRepo(db, cfg.MySQL.QueryTimeouts),
+		ActorID:  *actorID,
+		Currency: *currency,
+		DryRun:   *dryRun,
+	}
+
+	report, err := ingester.Ingest(context.Background(), bufio.NewReader(file))
+	printReport(report)
+	if err != nil {
+		log.Fatalf("invalid ONIX message: %s", err)
+	}
+	if report.Skipped > 0 {
+		os.Exit(1)
+	}
+}
+
+func printReport(report *onix.Report) {
+	if report.DryRun {
+		fmt.Println("dry run, nothing was written")
+	}
+	fmt.Printf("products: %d, created: %d, updated: %d, skipped: %d\n",
+		report.Products, report.Created, report.Updated, report.Skipped)
+
+	for _, result := range report.Results {
+		for _, problem := range result.Problems {
+			fmt.Printf("%s (%s): %s\n", result.Reference, result.ISBN, problem)
+		}
+	}
+
+	paths := make([]string, 0, len(report.Unmapped))
+	for path := range report.Unmapped {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("unmapped field %s: %d occurrences\n", path, report.Unmapped[path])
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `promotions`;
+
+DROP TABLE IF EXISTS `price_history`;
+
+ALTER TABLE `books` DROP COLUMN `price`;
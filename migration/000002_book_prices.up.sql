@@ -0,0 +1,32 @@
+ALTER TABLE `books`
+  ADD COLUMN `price` BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+CREATE TABLE `price_history` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `book_id` INT UNSIGNED NOT NULL,
+  `price` BIGINT UNSIGNED NOT NULL,
+  `changed_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`id`),
+  INDEX `price_history_book_changed` (`book_id`, `changed_at`),
+
+  CONSTRAINT `price_history_constr_book`
+    FOREIGN KEY (`book_id`) REFERENCES `books`(`id`)
+    ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE `promotions` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `book_id` INT UNSIGNED NOT NULL,
+  `kind` ENUM('percentage', 'fixed') NOT NULL,
+  `amount` BIGINT UNSIGNED NOT NULL,
+  `starts_at` DATETIME NOT NULL,
+  `ends_at` DATETIME NOT NULL,
+
+  PRIMARY KEY (`id`),
+  INDEX `promotions_book_window` (`book_id`, `starts_at`, `ends_at`),
+
+  CONSTRAINT `promotions_constr_book`
+    FOREIGN KEY (`book_id`) REFERENCES `books`(`id`)
+    ON DELETE CASCADE ON UPDATE CASCADE
+);
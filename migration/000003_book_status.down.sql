@@ -0,0 +1,3 @@
+DROP INDEX `books_status` ON `books`;
+
+ALTER TABLE `books` DROP COLUMN `status`;
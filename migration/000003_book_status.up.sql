@@ -0,0 +1,8 @@
+-- books already in the catalog stay public, new ones start as drafts
+ALTER TABLE `books`
+  ADD COLUMN `status` ENUM('draft', 'pending_review', 'published', 'out_of_print', 'withdrawn') NOT NULL DEFAULT 'published';
+
+ALTER TABLE `books`
+  ALTER COLUMN `status` SET DEFAULT 'draft';
+
+CREATE INDEX `books_status` ON `books` (`status`);
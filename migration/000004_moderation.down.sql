@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `moderations`;
+
+ALTER TABLE `books` DROP COLUMN `status_changed_at`;
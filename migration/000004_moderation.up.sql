@@ -0,0 +1,18 @@
+ALTER TABLE `books`
+  ADD COLUMN `status_changed_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE `moderations` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `book_id` INT UNSIGNED NOT NULL,
+  `moderator_id` INT UNSIGNED NOT NULL,
+  `decision` ENUM('approved', 'rejected') NOT NULL,
+  `reason` TEXT NOT NULL,
+  `created_at` DATETIME NOT NULL,
+
+  PRIMARY KEY (`id`),
+  INDEX `moderations_book` (`book_id`, `created_at`),
+
+  CONSTRAINT `moderations_constr_book`
+    FOREIGN KEY (`book_id`) REFERENCES `books`(`id`)
+    ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,15 @@
+CREATE TABLE `audit_log` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `actor_id` INT UNSIGNED NOT NULL,
+  `action` ENUM('create', 'update', 'delete') NOT NULL,
+  `entity` ENUM('book', 'author', 'publisher') NOT NULL,
+  `entity_id` INT UNSIGNED NOT NULL,
+  `state_before` JSON,
+  `state_after` JSON,
+  `created_at` DATETIME NOT NULL,
+
+  PRIMARY KEY (`id`),
+  INDEX `audit_log_entity` (`entity`, `entity_id`, `created_at`),
+  INDEX `audit_log_actor` (`actor_id`, `created_at`),
+  INDEX `audit_log_created` (`created_at`)
+);
@@ -0,0 +1,11 @@
+ALTER TABLE `books`
+  DROP COLUMN `updated_at`,
+  DROP COLUMN `version`;
+
+ALTER TABLE `authors`
+  DROP COLUMN `updated_at`,
+  DROP COLUMN `version`;
+
+ALTER TABLE `publishers`
+  DROP COLUMN `updated_at`,
+  DROP COLUMN `version`;
@@ -0,0 +1,11 @@
+ALTER TABLE `publishers`
+  ADD COLUMN `version` INT UNSIGNED NOT NULL DEFAULT 1,
+  ADD COLUMN `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE `authors`
+  ADD COLUMN `version` INT UNSIGNED NOT NULL DEFAULT 1,
+  ADD COLUMN `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE `books`
+  ADD COLUMN `version` INT UNSIGNED NOT NULL DEFAULT 1,
+  ADD COLUMN `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
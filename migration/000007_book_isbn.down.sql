@@ -0,0 +1,4 @@
+DROP INDEX `books_isbn` ON `books`;
+
+ALTER TABLE `books`
+  DROP COLUMN `isbn`;
@@ -0,0 +1,5 @@
+-- books predating ISBNs keep it empty, hence NULL rather than ''
+ALTER TABLE `books`
+  ADD COLUMN `isbn` CHAR(13) NULL;
+
+CREATE UNIQUE INDEX `books_isbn` ON `books` (`isbn`);
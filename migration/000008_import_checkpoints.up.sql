@@ -0,0 +1,9 @@
+-- the catalog importer records the last line of every batch it imports in
+-- the batch's own transaction, so a resumed import never saves a book twice
+CREATE TABLE `import_checkpoints` (
+  `name` VARCHAR(255) NOT NULL,
+  `line` INT UNSIGNED NOT NULL,
+  `updated_at` DATETIME NOT NULL,
+
+  PRIMARY KEY (`name`)
+);
@@ -0,0 +1,10 @@
+// Package migration embeds the schema migrations, for the binary to apply
+// them on its own.
+package migration
+
+import "embed"
+
+// Files holds the migrations, as NNNNNN_name.up.sql and NNNNNN_name.down.sql
+//
+//go:embed *.sql
+var Files embed.FS
@@ -1,9 +1,11 @@
 package domain
 
 type BookDenormalized struct {
-	Book      Book      `json:"book"`
-	Authors   []Author  `json:"authors"`
-	Publisher Publisher `json:"publisher"`
+	Book           Book        `json:"book"`
+	Authors        []Author    `json:"authors"`
+	Publisher      Publisher   `json:"publisher"`
+	Promotions     []Promotion `json:"promotions,omitempty"`
+	EffectivePrice int64       `json:"effective_price"`
 }
 
 type PublisherDenormalized struct {
@@ -17,6 +19,13 @@ type AuthorDenormalized struct {
 	Books  []Book `json:"books"`
 }
 
+type Submission struct {
+	Book        Book      `json:"book"`
+	Publisher   Publisher `json:"publisher"`
+	SubmittedBy int64     `json:"submitted_by"`
+	SubmittedAt string    `json:"submitted_at"`
+}
+
 type Published struct {
 	AuthorID    int64 `json:"author_id"`
 	PublisherID int64 `json:"publisher_id"`
@@ -0,0 +1,50 @@
+package domain
+
+import "encoding/json"
+
+const (
+	EntityBook      = "book"
+	EntityAuthor    = "author"
+	EntityPublisher = "publisher"
+
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	ActorID   int64           `json:"actor_id"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int64           `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// BookStatusState and BookPriceState are what the audit log records of the
+// changes that only touch a book's status or price, the whole record being
+// recorded for the others
+type BookStatusState struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+type BookPriceState struct {
+	ID    int64 `json:"id"`
+	Price int64 `json:"price"`
+}
+
+// AuditFilter narrows down the audit log, zero values are ignored
+type AuditFilter struct {
+	Entity  string
+	ActorID int64
+	From    string
+	To      string
+	Limit   int
+}
+
+func IsAuditedEntity(entity string) bool {
+	return entity == EntityBook || entity == EntityAuthor || entity == EntityPublisher
+}
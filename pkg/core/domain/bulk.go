@@ -0,0 +1,14 @@
+package domain
+
+const (
+	// BulkAtomic creates every book of the batch or none of them
+	BulkAtomic = "atomic"
+	// BulkBestEffort creates the valid books of the batch and reports the rest
+	BulkBestEffort = "best_effort"
+
+	MaxBulkBooks = 500
+)
+
+func IsValidBulkMode(mode string) bool {
+	return mode == BulkAtomic || mode == BulkBestEffort
+}
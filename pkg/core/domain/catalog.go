@@ -0,0 +1,16 @@
+package domain
+
+// CatalogEntry is a book along with its publisher's and authors' names, as
+// catalog dumps carry them
+type CatalogEntry struct {
+	Book      Book
+	Publisher Publisher
+	Authors   []Author
+}
+
+// ImportCheckpoint is how far the catalog import tracked as Name went, Line
+// being the last line of the file whose book was saved
+type ImportCheckpoint struct {
+	Name string
+	Line int
+}
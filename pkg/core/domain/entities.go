@@ -7,10 +7,13 @@ type Author struct {
 	Biography string  `json:"biography,omitempty"`
 	Birthday  string  `json:"birthday,omitempty"`
 	Death     *string `json:"death,omitempty"`
+	Version   int64   `json:"version,omitempty"`
+	UpdatedAt string  `json:"updated_at,omitempty"`
 }
 
 type Book struct {
 	ID               int64   `json:"id,omitempty"`
+	ISBN             string  `json:"isbn,omitempty"`
 	Title            string  `json:"title,omitempty"`
 	OriginalRelease  string  `json:"original_release,omitempty"`
 	Description      string  `json:"description,omitempty"`
@@ -20,6 +23,11 @@ type Book struct {
 	Pages            int64   `json:"pages,omitempty"`
 	AuthorID         []int64 `json:"author_id,omitempty"`
 	SellerID         int64   `json:"seller_id,omitempty"`
+	// Price is expressed in cents
+	Price     int64  `json:"price,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Version   int64  `json:"version,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
 }
 
 type Publisher struct {
@@ -28,6 +36,31 @@ type Publisher struct {
 	Description string `json:"description,omitempty"`
 	Slogan      string `json:"slogan,omitempty"`
 	Founded     string `json:"founded,omitempty"`
+	Version     int64  `json:"version,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+type Promotion struct {
+	ID       int64  `json:"id,omitempty"`
+	BookID   int64  `json:"book_id,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Amount   int64  `json:"amount,omitempty"`
+	StartsAt string `json:"starts_at,omitempty"`
+	EndsAt   string `json:"ends_at,omitempty"`
+}
+
+type Moderation struct {
+	ID          int64  `json:"id,omitempty"`
+	BookID      int64  `json:"book_id,omitempty"`
+	ModeratorID int64  `json:"moderator_id,omitempty"`
+	Decision    string `json:"decision,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+}
+
+type PriceChange struct {
+	Price     int64  `json:"price"`
+	ChangedAt string `json:"changed_at"`
 }
 
 /*
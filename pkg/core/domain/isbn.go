@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizeISBN returns the ISBN-13 without hyphens nor spaces, ISBN-10s are
+// converted to their ISBN-13
+func NormalizeISBN(isbn string) (string, error) {
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	switch len(isbn) {
+	case 10:
+		if !validISBN10(isbn) {
+			return "", errors.New("invalid ISBN-10 check digit")
+		}
+		isbn = "978" + isbn[:9]
+		return isbn + string(isbn13CheckDigit(isbn)), nil
+	case 13:
+		if !isDigits(isbn) || isbn13CheckDigit(isbn[:12]) != isbn[12] {
+			return "", errors.New("invalid ISBN-13 check digit")
+		}
+		return isbn, nil
+	}
+	return "", errors.New("an ISBN has either 10 or 13 digits")
+}
+
+func isbn13CheckDigit(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(first12[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func validISBN10(isbn string) bool {
+	if !isDigits(isbn[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(isbn[i]-'0') * (10 - i)
+	}
+	switch check := isbn[9]; {
+	case check == 'X':
+		sum += 10
+	case check >= '0' && check <= '9':
+		sum += int(check - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package domain
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListOptions paginates listings by id, AfterID being the last id of the
+// previous page
+type ListOptions struct {
+	AfterID int64
+	Limit   int
+}
+
+// SellerBooksFilter narrows the listing of the books of a seller
+type SellerBooksFilter struct {
+	SellerID int64
+	// Status only lists the books in it, books in any status when empty
+	Status string
+	// PublicOnly leaves out the books only their seller and admins can see
+	PublicOnly bool
+}
+
+func (o ListOptions) Normalize() ListOptions {
+	if o.AfterID < 0 {
+		o.AfterID = 0
+	}
+	if o.Limit <= 0 {
+		o.Limit = DefaultListLimit
+	}
+	if o.Limit > MaxListLimit {
+		o.Limit = MaxListLimit
+	}
+	return o
+}
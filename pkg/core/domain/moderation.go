@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+func (m *Moderation) Validate() error {
+	m.Reason = strings.TrimSpace(m.Reason)
+
+	switch m.Decision {
+	case DecisionApproved:
+	case DecisionRejected:
+		if m.Reason == "" {
+			return errors.New("a reason is required to reject a book")
+		}
+	default:
+		return errors.New("decision must be either approved or rejected")
+	}
+	return nil
+}
+
+// TargetStatus returns the status a pending book is moved to by the decision,
+// rejected books go back to their seller as drafts
+func (m Moderation) TargetStatus() string {
+	if m.Decision == DecisionApproved {
+		return StatusPublished
+	}
+	return StatusDraft
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	PromotionPercentage = "percentage"
+	PromotionFixed      = "fixed"
+
+	// TimestampLayout is the layout in which DATETIME columns are read and written
+	TimestampLayout = "2006-01-02 15:04:05"
+	// DateLayout is the layout in which DATE columns are read and written
+	DateLayout = "2006-01-02"
+)
+
+func (p *Promotion) Validate() error {
+	switch p.Kind {
+	case PromotionPercentage:
+		if p.Amount <= 0 || p.Amount > 100 {
+			return errors.New("percentage promotions must be between 1 and 100")
+		}
+	case PromotionFixed:
+		if p.Amount <= 0 {
+			return errors.New("fixed promotions must have a positive amount")
+		}
+	default:
+		return errors.New("promotion kind must be either percentage or fixed")
+	}
+
+	startsAt, err := time.Parse(TimestampLayout, p.StartsAt)
+	if err != nil {
+		return errors.New("invalid starts_at timestamp")
+	}
+	endsAt, err := time.Parse(TimestampLayout, p.EndsAt)
+	if err != nil {
+		return errors.New("invalid ends_at timestamp")
+	}
+	if !endsAt.After(startsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	return nil
+}
+
+// Apply returns the price after discounting the promotion, never below zero
+func (p Promotion) Apply(price int64) int64 {
+	var discounted int64
+	switch p.Kind {
+	case PromotionPercentage:
+		discounted = price - price*p.Amount/100
+	case PromotionFixed:
+		discounted = price - p.Amount
+	default:
+		return price
+	}
+
+	if discounted < 0 {
+		return 0
+	}
+	return discounted
+}
+
+// EffectivePrice returns the lowest price obtainable from the active promotions,
+// if several of them overlap the customer gets the best one
+func EffectivePrice(price int64, active []Promotion) int64 {
+	effective := price
+	for _, promotion := range active {
+		if discounted := promotion.Apply(price); discounted < effective {
+			effective = discounted
+		}
+	}
+	return effective
+}
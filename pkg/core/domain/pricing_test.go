@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion Promotion
+		valid     bool
+	}{
+		{"Percentage", Promotion{Kind: PromotionPercentage, Amount: 25, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, true},
+		{"FullPercentage", Promotion{Kind: PromotionPercentage, Amount: 100, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, true},
+		{"Fixed", Promotion{Kind: PromotionFixed, Amount: 500, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, true},
+		{"NoPercentage", Promotion{Kind: PromotionPercentage, Amount: 0, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, false},
+		{"PercentageOver100", Promotion{Kind: PromotionPercentage, Amount: 101, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, false},
+		{"NegativeFixed", Promotion{Kind: PromotionFixed, Amount: -1, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, false},
+		{"UnknownKind", Promotion{Kind: "bogo", Amount: 1, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-27 00:00:00"}, false},
+		{"InvalidStartsAt", Promotion{Kind: PromotionFixed, Amount: 500, StartsAt: "2021-12-20", EndsAt: "2021-12-27 00:00:00"}, false},
+		{"InvalidEndsAt", Promotion{Kind: PromotionFixed, Amount: 500, StartsAt: "2021-12-20 00:00:00", EndsAt: "next week"}, false},
+		{"EndsAtStart", Promotion{Kind: PromotionFixed, Amount: 500, StartsAt: "2021-12-20 00:00:00", EndsAt: "2021-12-20 00:00:00"}, false},
+		{"EndsBeforeStart", Promotion{Kind: PromotionFixed, Amount: 500, StartsAt: "2021-12-27 00:00:00", EndsAt: "2021-12-20 00:00:00"}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.promotion.Validate()
+
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestPromotionApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion Promotion
+		price     int64
+		want      int64
+	}{
+		{"Percentage", Promotion{Kind: PromotionPercentage, Amount: 25}, 2000, 1500},
+		{"PercentageRoundsInFavorOfTheStore", Promotion{Kind: PromotionPercentage, Amount: 10}, 1999, 1800},
+		{"FullPercentage", Promotion{Kind: PromotionPercentage, Amount: 100}, 2000, 0},
+		{"Fixed", Promotion{Kind: PromotionFixed, Amount: 500}, 2000, 1500},
+		{"FixedOverPrice", Promotion{Kind: PromotionFixed, Amount: 2500}, 2000, 0},
+		{"UnknownKind", Promotion{Kind: "bogo", Amount: 500}, 2000, 2000},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualValues(t, tt.want, tt.promotion.Apply(tt.price))
+		})
+	}
+}
+
+func TestEffectivePrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		active []Promotion
+		want   int64
+	}{
+		{"NoPromotion", nil, 2000},
+		{"OnePromotion", []Promotion{{Kind: PromotionFixed, Amount: 300}}, 1700},
+		{"BestPromotionWins", []Promotion{
+			{Kind: PromotionFixed, Amount: 300},
+			{Kind: PromotionPercentage, Amount: 25},
+			{Kind: PromotionFixed, Amount: 100},
+		}, 1500},
+		{"PromotionsDontStack", []Promotion{
+			{Kind: PromotionPercentage, Amount: 50},
+			{Kind: PromotionPercentage, Amount: 50},
+		}, 1000},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualValues(t, tt.want, EffectivePrice(2000, tt.active))
+		})
+	}
+}
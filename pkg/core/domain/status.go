@@ -0,0 +1,58 @@
+package domain
+
+import "fmt"
+
+const (
+	StatusDraft         = "draft"
+	StatusPendingReview = "pending_review"
+	StatusPublished     = "published"
+	StatusOutOfPrint    = "out_of_print"
+	StatusWithdrawn     = "withdrawn"
+)
+
+// transitions holds, for every status, the statuses a book can be moved to
+var transitions = map[string][]string{
+	StatusDraft:         {StatusPendingReview, StatusWithdrawn},
+	StatusPendingReview: {StatusPublished, StatusDraft, StatusWithdrawn},
+	StatusPublished:     {StatusOutOfPrint, StatusWithdrawn},
+	StatusOutOfPrint:    {StatusPublished, StatusWithdrawn},
+	StatusWithdrawn:     {StatusDraft},
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// IsPublicStatus reports whether books in the given status can be read by anyone
+func IsPublicStatus(status string) bool {
+	return status == StatusPublished || status == StatusOutOfPrint
+}
+
+// RequiresReviewer reports whether moving a book into the given status is
+// reserved to admins rather than to the book's seller
+func RequiresReviewer(status string) bool {
+	return status == StatusPublished || status == StatusOutOfPrint
+}
+
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the book to the given status if the lifecycle allows it
+func (b *Book) TransitionTo(status string) error {
+	if !IsValidStatus(status) {
+		return fmt.Errorf("unknown status %q", status)
+	}
+	if !CanTransition(b.Status, status) {
+		return fmt.Errorf("a book can't go from %s to %s", b.Status, status)
+	}
+
+	b.Status = status
+	return nil
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionTo(t *testing.T) {
+	statuses := []string{StatusDraft, StatusPendingReview, StatusPublished, StatusOutOfPrint, StatusWithdrawn}
+	allowed := map[[2]string]bool{
+		{StatusDraft, StatusPendingReview}:     true,
+		{StatusDraft, StatusWithdrawn}:         true,
+		{StatusPendingReview, StatusPublished}: true,
+		{StatusPendingReview, StatusDraft}:     true,
+		{StatusPendingReview, StatusWithdrawn}: true,
+		{StatusPublished, StatusOutOfPrint}:    true,
+		{StatusPublished, StatusWithdrawn}:     true,
+		{StatusOutOfPrint, StatusPublished}:    true,
+		{StatusOutOfPrint, StatusWithdrawn}:    true,
+		{StatusWithdrawn, StatusDraft}:         true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			from, to := from, to
+			t.Run(from+"To"+to, func(t *testing.T) {
+				book := Book{Status: from}
+
+				err := book.TransitionTo(to)
+
+				if allowed[[2]string{from, to}] {
+					assert.Nil(t, err)
+					assert.EqualValues(t, to, book.Status)
+				} else {
+					assert.NotNil(t, err)
+					assert.EqualValues(t, from, book.Status)
+				}
+			})
+		}
+	}
+
+	t.Run("UnknownStatus", func(t *testing.T) {
+		book := Book{Status: StatusDraft}
+
+		err := book.TransitionTo("archived")
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, StatusDraft, book.Status)
+	})
+}
@@ -1,20 +1,51 @@
 package ports
 
 import (
+	"context"
+
 	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
 	"github.com/FacuBar/bookstore_utils-go/rest_errors"
 )
 
 type BooksRepositoryInterface interface {
-	SaveAuthor(*domain.Author) rest_errors.RestErr
-	UpdateAuthor(*domain.Author) rest_errors.RestErr
-	GetAuthorById(int64) (*domain.AuthorDenormalized, rest_errors.RestErr)
+	SaveAuthor(ctx context.Context, actorID int64, author *domain.Author) rest_errors.RestErr
+	UpdateAuthor(ctx context.Context, actorID int64, author *domain.Author) rest_errors.RestErr
+	GetAuthorById(context.Context, int64) (*domain.AuthorDenormalized, rest_errors.RestErr)
+	ListAuthors(context.Context, domain.ListOptions) ([]domain.Author, rest_errors.RestErr)
+	GetAuthorByName(ctx context.Context, firstName string, lastName string) (*domain.Author, rest_errors.RestErr)
+
+	SavePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) rest_errors.RestErr
+	UpdatePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) rest_errors.RestErr
+	GetPublisherById(context.Context, int64) (*domain.PublisherDenormalized, rest_errors.RestErr)
+	ListPublishers(context.Context, domain.ListOptions) ([]domain.Publisher, rest_errors.RestErr)
+	GetPublisherByName(ctx context.Context, name string) (*domain.Publisher, rest_errors.RestErr)
+
+	SaveBook(ctx context.Context, actorID int64, book *domain.Book) rest_errors.RestErr
+	SaveBooks(ctx context.Context, actorID int64, books []domain.Book) rest_errors.RestErr
+	SaveImportBatch(ctx context.Context, actorID int64, books []domain.Book, checkpoint domain.ImportCheckpoint) rest_errors.RestErr
+	GetImportCheckpoint(ctx context.Context, name string) (int, rest_errors.RestErr)
+	UpdateBook(ctx context.Context, actorID int64, book *domain.Book) rest_errors.RestErr
+	GetBookById(context.Context, int64) (*domain.BookDenormalized, rest_errors.RestErr)
+	GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, rest_errors.RestErr)
+	ListBooks(context.Context, domain.ListOptions) ([]domain.Book, rest_errors.RestErr)
+	ListSellerBooks(context.Context, domain.SellerBooksFilter, domain.ListOptions) ([]domain.Book, rest_errors.RestErr)
+	UpdateBookStatus(ctx context.Context, actorID int64, bookID int64, version int64, from string, to string) rest_errors.RestErr
+
+	GetPublishersByIds(context.Context, []int64) (map[int64]domain.Publisher, rest_errors.RestErr)
+	GetAuthorsByBookIds(context.Context, []int64) (map[int64][]domain.Author, rest_errors.RestErr)
+	GetAuthorsByPublisherIds(context.Context, []int64) (map[int64][]domain.Author, rest_errors.RestErr)
+	GetBooksByAuthorIds(context.Context, []int64) (map[int64][]domain.Book, rest_errors.RestErr)
+	GetBooksByPublisherIds(context.Context, []int64) (map[int64][]domain.Book, rest_errors.RestErr)
+
+	GetPendingBooks(context.Context) ([]domain.Submission, rest_errors.RestErr)
+	ModerateBook(context.Context, *domain.Moderation) rest_errors.RestErr
+	GetModerations(context.Context, int64) ([]domain.Moderation, rest_errors.RestErr)
+
+	UpdateBookPrice(ctx context.Context, actorID int64, bookID int64, version int64, price int64) rest_errors.RestErr
+	GetPriceHistory(context.Context, int64) ([]domain.PriceChange, rest_errors.RestErr)
+	SavePromotion(ctx context.Context, actorID int64, promotion *domain.Promotion) rest_errors.RestErr
 
-	SavePublisher(*domain.Publisher) rest_errors.RestErr
-	UpdatePublisher(*domain.Publisher) rest_errors.RestErr
-	GetPublisherById(int64) (*domain.PublisherDenormalized, rest_errors.RestErr)
+	GetAuditLog(context.Context, domain.AuditFilter) ([]domain.AuditEntry, rest_errors.RestErr)
 
-	SaveBook(*domain.Book) rest_errors.RestErr
-	UpdateBook(*domain.Book) rest_errors.RestErr
-	GetBookById(int64) (*domain.BookDenormalized, rest_errors.RestErr)
+	ExportBooks(context.Context, func(domain.CatalogEntry) error) rest_errors.RestErr
 }
@@ -0,0 +1,48 @@
+package biblio
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+)
+
+// dublinCore is an oai_dc record, the simple Dublin Core profile OAI-PMH
+// harvesters expect. encoding/xml doesn't write prefixes, so they're part of
+// the element names and the namespaces are declared by hand.
+type dublinCore struct {
+	XMLName     xml.Name `xml:"oai_dc:dc"`
+	OAIDC       string   `xml:"xmlns:oai_dc,attr"`
+	DC          string   `xml:"xmlns:dc,attr"`
+	Title       string   `xml:"dc:title"`
+	Creators    []string `xml:"dc:creator"`
+	Publisher   string   `xml:"dc:publisher,omitempty"`
+	Date        string   `xml:"dc:date,omitempty"`
+	Description string   `xml:"dc:description,omitempty"`
+	Identifiers []string `xml:"dc:identifier"`
+	Type        string   `xml:"dc:type"`
+	Format      string   `xml:"dc:format,omitempty"`
+}
+
+// DublinCore maps a book to a Dublin Core record
+func DublinCore(book domain.BookDenormalized) interface{} {
+	record := dublinCore{
+		OAIDC:       OAIDCNamespace,
+		DC:          DCNamespace,
+		Title:       book.Book.Title,
+		Publisher:   book.Publisher.Name,
+		Date:        book.Book.Published,
+		Description: firstNonEmpty(book.Book.Description, book.Book.ShortDescription),
+		Type:        "Text",
+	}
+	for _, author := range book.Authors {
+		record.Creators = append(record.Creators, author.LastName+", "+author.FirstName)
+	}
+	if book.Book.ISBN != "" {
+		record.Identifiers = append(record.Identifiers, "urn:isbn:"+book.Book.ISBN)
+	}
+	if book.Book.Pages > 0 {
+		record.Format = fmt.Sprintf("%d pages", book.Book.Pages)
+	}
+	return record
+}
@@ -0,0 +1,207 @@
+// Package biblio renders books in the bibliographic formats libraries
+// exchange, MARC 21 and Dublin Core.
+package biblio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+)
+
+// Subfield is a coded piece of a MARC data field
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// Field is either a control field, 001 to 009, which only has a Value, or a
+// data field with indicators and subfields
+type Field struct {
+	Tag       string
+	Value     string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+func (f Field) IsControl() bool {
+	return f.Tag < "010"
+}
+
+// Record is a MARC 21 bibliographic record, its leader is computed when the
+// record is encoded
+type Record struct {
+	Fields []Field
+}
+
+// ISO 2709 delimiters
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+
+	leaderLength   = 24
+	directoryEntry = 12
+	maxRecord      = 99999
+)
+
+// MARC maps a book to a MARC 21 record. Its first author is the main entry,
+// the rest are added entries.
+func MARC(book domain.BookDenormalized) Record {
+	var fields []Field
+	control := func(tag, value string) {
+		fields = append(fields, Field{Tag: tag, Value: value})
+	}
+	data := func(tag string, ind1, ind2 byte, subfields ...Subfield) {
+		var kept []Subfield
+		for _, subfield := range subfields {
+			if strings.TrimSpace(subfield.Value) != "" {
+				kept = append(kept, subfield)
+			}
+		}
+		if len(kept) > 0 {
+			fields = append(fields, Field{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: kept})
+		}
+	}
+
+	control("001", strconv.FormatInt(book.Book.ID, 10))
+	if updated := digits(book.Book.UpdatedAt); len(updated) == 14 {
+		control("005", updated+".0")
+	}
+	control("008", fixedLengthData(book.Book))
+
+	data("020", ' ', ' ', Subfield{'a', book.Book.ISBN})
+
+	if len(book.Authors) > 0 {
+		author := book.Authors[0]
+		data("100", '1', ' ', Subfield{'a', author.LastName + ", " + author.FirstName}, Subfield{'e', "author."})
+	}
+
+	titleInd1 := byte('0')
+	if len(book.Authors) > 0 {
+		titleInd1 = '1'
+	}
+	data("245", titleInd1, '0', Subfield{'a', book.Book.Title})
+
+	data("264", ' ', '1',
+		Subfield{'b', book.Publisher.Name},
+		Subfield{'c', year(book.Book.Published)},
+	)
+
+	if book.Book.Pages > 0 {
+		data("300", ' ', ' ', Subfield{'a', fmt.Sprintf("%d pages", book.Book.Pages)})
+	}
+
+	if original := year(book.Book.OriginalRelease); original != "" && original != year(book.Book.Published) {
+		data("500", ' ', ' ', Subfield{'a', "Originally published in " + original + "."})
+	}
+	data("520", ' ', ' ', Subfield{'a', firstNonEmpty(book.Book.Description, book.Book.ShortDescription)})
+
+	if len(book.Authors) > 1 {
+		for _, author := range book.Authors[1:] {
+			data("700", '1', ' ', Subfield{'a', author.LastName + ", " + author.FirstName}, Subfield{'e', "author."})
+		}
+	}
+
+	return Record{Fields: fields}
+}
+
+// fixedLengthData builds the 008 field of a book, only the publication date
+// and language positions are coded, the rest is left as no attempt to code
+func fixedLengthData(book domain.Book) string {
+	entered := digits(book.UpdatedAt)
+	if len(entered) >= 8 {
+		entered = entered[2:8]
+	} else {
+		entered = "||||||"
+	}
+
+	date := year(book.Published)
+	if date == "" {
+		date = "uuuu"
+	}
+
+	return entered + // 00-05 date entered on file
+		"s" + date + "    " + // 06-14 single known date
+		"xx " + // 15-17 place of publication unknown
+		strings.Repeat("|", 17) + // 18-34 book material characteristics
+		"und" + // 35-37 undetermined language
+		" d" // 38-39 not modified, other cataloging source
+}
+
+// Leader returns the record's leader given its encoded length and the base
+// address of its data
+func leader(length, baseAddress int) string {
+	return fmt.Sprintf("%05dnam a22%05d7u 4500", length, baseAddress)
+}
+
+// MarshalBinary encodes the record in the ISO 2709 exchange format
+func (r Record) MarshalBinary() ([]byte, error) {
+	var directory, fields bytes.Buffer
+	for _, field := range r.Fields {
+		start := fields.Len()
+		if field.IsControl() {
+			fields.WriteString(field.Value)
+		} else {
+			fields.WriteByte(field.Ind1)
+			fields.WriteByte(field.Ind2)
+			for _, subfield := range field.Subfields {
+				fields.WriteByte(subfieldDelimiter)
+				fields.WriteByte(subfield.Code)
+				fields.WriteString(subfield.Value)
+			}
+		}
+		fields.WriteByte(fieldTerminator)
+
+		length := fields.Len() - start
+		if length > 9999 {
+			return nil, fmt.Errorf("field %s is too long for ISO 2709", field.Tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", field.Tag, length, start)
+	}
+	directory.WriteByte(fieldTerminator)
+
+	baseAddress := leaderLength + directory.Len()
+	length := baseAddress + fields.Len() + 1
+	if length > maxRecord {
+		return nil, errors.New("record is too long for ISO 2709")
+	}
+
+	encoded := make([]byte, 0, length)
+	encoded = append(encoded, leader(length, baseAddress)...)
+	encoded = append(encoded, directory.Bytes()...)
+	encoded = append(encoded, fields.Bytes()...)
+	return append(encoded, recordTerminator), nil
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// year returns the year of a YYYY-MM-DD date
+func year(date string) string {
+	if len(date) >= 4 {
+		if _, err := strconv.Atoi(date[:4]); err == nil {
+			return date[:4]
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package biblio
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var book = domain.BookDenormalized{
+	Book: domain.Book{
+		ID:              7,
+		ISBN:            "9780802130204",
+		Title:           "Blood Meridian",
+		OriginalRelease: "1985-04-01",
+		Description:     "An epic novel of the violence of the American West.",
+		Published:       "2010-06-01",
+		Pages:           368,
+		UpdatedAt:       "2021-09-14 10:32:05",
+	},
+	Publisher: domain.Publisher{ID: 1, Name: "Grove Atlantic"},
+	Authors: []domain.Author{
+		{ID: 2, FirstName: "Cormac", LastName: "McCarthy"},
+		{ID: 3, FirstName: "Harold", LastName: "Bloom"},
+	},
+}
+
+func field(r Record, tag string) (Field, bool) {
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+func TestMARC(t *testing.T) {
+	record := MARC(book)
+
+	control, _ := field(record, "001")
+	assert.EqualValues(t, "7", control.Value)
+	control, _ = field(record, "005")
+	assert.EqualValues(t, "20210914103205.0", control.Value)
+	control, _ = field(record, "008")
+	assert.Len(t, control.Value, 40)
+	assert.EqualValues(t, "210914s2010", control.Value[:11])
+
+	main, _ := field(record, "100")
+	assert.EqualValues(t, []Subfield{{'a', "McCarthy, Cormac"}, {'e', "author."}}, main.Subfields)
+	title, _ := field(record, "245")
+	assert.EqualValues(t, '1', title.Ind1)
+	added, _ := field(record, "700")
+	assert.EqualValues(t, "Bloom, Harold", added.Subfields[0].Value)
+	note, _ := field(record, "500")
+	assert.EqualValues(t, "Originally published in 1985.", note.Subfields[0].Value)
+
+	t.Run("Anonymous", func(t *testing.T) {
+		anonymous := book
+		anonymous.Authors = nil
+		anonymous.Book.ISBN = ""
+
+		record := MARC(anonymous)
+		_, hasMain := field(record, "100")
+		_, hasISBN := field(record, "020")
+		title, _ := field(record, "245")
+
+		assert.False(t, hasMain)
+		assert.False(t, hasISBN)
+		assert.EqualValues(t, '0', title.Ind1)
+	})
+}
+
+func TestMarshalBinary(t *testing.T) {
+	record := MARC(book)
+	encoded, err := record.MarshalBinary()
+	assert.Nil(t, err)
+
+	length, _ := strconv.Atoi(string(encoded[:5]))
+	baseAddress, _ := strconv.Atoi(string(encoded[12:17]))
+	assert.EqualValues(t, len(encoded), length)
+	assert.EqualValues(t, recordTerminator, encoded[len(encoded)-1])
+	assert.EqualValues(t, fieldTerminator, encoded[baseAddress-1])
+
+	directory := string(encoded[leaderLength : baseAddress-1])
+	assert.Len(t, directory, directoryEntry*len(record.Fields))
+
+	// every directory entry points at its field
+	for i, f := range record.Fields {
+		entry := directory[i*directoryEntry : (i+1)*directoryEntry]
+		fieldLength, _ := strconv.Atoi(entry[3:7])
+		start, _ := strconv.Atoi(entry[7:])
+		data := string(encoded[baseAddress+start : baseAddress+start+fieldLength])
+
+		assert.EqualValues(t, f.Tag, entry[:3])
+		assert.True(t, strings.HasSuffix(data, string(rune(fieldTerminator))))
+		if f.IsControl() {
+			assert.EqualValues(t, f.Value+string(rune(fieldTerminator)), data)
+		}
+	}
+}
+
+func TestMarshalMARCXML(t *testing.T) {
+	encoded, err := Marshal(book, FormatMARCXML)
+	assert.Nil(t, err)
+
+	var record struct {
+		XMLName       xml.Name `xml:"http://www.loc.gov/MARC21/slim record"`
+		Leader        string   `xml:"leader"`
+		ControlFields []struct {
+			Tag   string `xml:"tag,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"controlfield"`
+		DataFields []struct {
+			Tag       string `xml:"tag,attr"`
+			Ind1      string `xml:"ind1,attr"`
+			Subfields []struct {
+				Code  string `xml:"code,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"subfield"`
+		} `xml:"datafield"`
+	}
+	assert.Nil(t, xml.Unmarshal(encoded, &record))
+
+	binary, _ := MARC(book).MarshalBinary()
+	assert.EqualValues(t, string(binary[:leaderLength]), record.Leader)
+	assert.EqualValues(t, "001", record.ControlFields[0].Tag)
+	assert.EqualValues(t, "020", record.DataFields[0].Tag)
+	assert.EqualValues(t, "a", record.DataFields[0].Subfields[0].Code)
+	assert.EqualValues(t, "9780802130204", record.DataFields[0].Subfields[0].Value)
+}
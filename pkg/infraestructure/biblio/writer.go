@@ -0,0 +1,133 @@
+package biblio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+)
+
+const (
+	FormatMARCXML    = "marcxml"
+	FormatMARC       = "marc"
+	FormatDublinCore = "dc"
+)
+
+// Formats lists the formats this package renders
+var Formats = []string{FormatMARCXML, FormatMARC, FormatDublinCore}
+
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the media type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatMARCXML:
+		return "application/marcxml+xml"
+	case FormatMARC:
+		return "application/marc"
+	case FormatDublinCore:
+		return "application/dc+xml"
+	}
+	return "application/octet-stream"
+}
+
+// FormatOf returns the format a media type stands for, if any
+func FormatOf(contentType string) (string, bool) {
+	for _, format := range Formats {
+		if ContentType(format) == contentType {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// Marshal renders a single book in the given format
+func Marshal(book domain.BookDenormalized, format string) ([]byte, error) {
+	switch format {
+	case FormatMARC:
+		return MARC(book).MarshalBinary()
+	case FormatMARCXML, FormatDublinCore:
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		var record interface{} = MARC(book)
+		if format == FormatDublinCore {
+			record = DublinCore(book)
+		}
+		if err := xml.NewEncoder(&buf).Encode(record); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown bibliographic format %q", format)
+}
+
+// Writer renders books one after the other, Close must be called once
+// they're all written. Nothing reaches the underlying writer until the first
+// book is written or the writer is closed.
+type Writer interface {
+	Write(domain.BookDenormalized) error
+	Close() error
+}
+
+// NewWriter returns a writer of a collection of records. MARCXML records are
+// wrapped in a collection element, Dublin Core ones in a records element and
+// MARC records are written back to back, as ISO 2709 files hold them.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatMARC:
+		return &marcWriter{w: w}, nil
+	case FormatMARCXML:
+		root := xml.StartElement{
+			Name: xml.Name{Local: "collection"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: MARCXMLNamespace}},
+		}
+		return &xmlCollectionWriter{
+			collectionWriter: collectionWriter{w: w, e: xml.NewEncoder(w), root: root},
+			record: func(book domain.BookDenormalized) interface{} {
+				return MARC(book).marcXML()
+			},
+		}, nil
+	case FormatDublinCore:
+		root := xml.StartElement{Name: xml.Name{Local: "records"}}
+		return &xmlCollectionWriter{
+			collectionWriter: collectionWriter{w: w, e: xml.NewEncoder(w), root: root},
+			record:           DublinCore,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown bibliographic format %q", format)
+}
+
+type marcWriter struct {
+	w io.Writer
+}
+
+func (mw *marcWriter) Write(book domain.BookDenormalized) error {
+	encoded, err := MARC(book).MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("book %d: %w", book.Book.ID, err)
+	}
+	_, err = mw.w.Write(encoded)
+	return err
+}
+
+func (mw *marcWriter) Close() error {
+	return nil
+}
+
+type xmlCollectionWriter struct {
+	collectionWriter
+	record func(domain.BookDenormalized) interface{}
+}
+
+func (xw *xmlCollectionWriter) Write(book domain.BookDenormalized) error {
+	return xw.write(xw.record(book))
+}
@@ -0,0 +1,73 @@
+package biblio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalDublinCore(t *testing.T) {
+	encoded, err := Marshal(book, FormatDublinCore)
+	assert.Nil(t, err)
+
+	var record struct {
+		XMLName     xml.Name `xml:"http://www.openarchives.org/OAI/2.0/oai_dc/ dc"`
+		Title       string   `xml:"http://purl.org/dc/elements/1.1/ title"`
+		Creators    []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		Publisher   string   `xml:"http://purl.org/dc/elements/1.1/ publisher"`
+		Identifiers []string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+		Format      string   `xml:"http://purl.org/dc/elements/1.1/ format"`
+	}
+	assert.Nil(t, xml.Unmarshal(encoded, &record))
+
+	assert.EqualValues(t, "Blood Meridian", record.Title)
+	assert.EqualValues(t, []string{"McCarthy, Cormac", "Bloom, Harold"}, record.Creators)
+	assert.EqualValues(t, "Grove Atlantic", record.Publisher)
+	assert.EqualValues(t, []string{"urn:isbn:9780802130204"}, record.Identifiers)
+	assert.EqualValues(t, "368 pages", record.Format)
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("MARCXMLCollection", func(t *testing.T) {
+		var out bytes.Buffer
+		writer, err := NewWriter(&out, FormatMARCXML)
+		assert.Nil(t, err)
+		assert.Nil(t, writer.Write(book))
+		assert.Nil(t, writer.Write(book))
+		assert.Nil(t, writer.Close())
+
+		var collection struct {
+			XMLName xml.Name `xml:"http://www.loc.gov/MARC21/slim collection"`
+			Records []struct {
+				Leader string `xml:"leader"`
+			} `xml:"http://www.loc.gov/MARC21/slim record"`
+		}
+		assert.Nil(t, xml.Unmarshal(out.Bytes(), &collection))
+		assert.Len(t, collection.Records, 2)
+	})
+
+	t.Run("MARC", func(t *testing.T) {
+		var out bytes.Buffer
+		writer, _ := NewWriter(&out, FormatMARC)
+		assert.Nil(t, writer.Write(book))
+		assert.Nil(t, writer.Write(book))
+		assert.Nil(t, writer.Close())
+
+		assert.EqualValues(t, 2, bytes.Count(out.Bytes(), []byte{recordTerminator}))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var out bytes.Buffer
+		writer, _ := NewWriter(&out, FormatDublinCore)
+		assert.Nil(t, writer.Close())
+
+		assert.Contains(t, out.String(), "<records></records>")
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		_, err := NewWriter(&bytes.Buffer{}, "mods")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,103 @@
+package biblio
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const (
+	MARCXMLNamespace = "http://www.loc.gov/MARC21/slim"
+	OAIDCNamespace   = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	DCNamespace      = "http://purl.org/dc/elements/1.1/"
+)
+
+type marcXMLSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcXMLControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcXMLDataField struct {
+	Tag       string            `xml:"tag,attr"`
+	Ind1      string            `xml:"ind1,attr"`
+	Ind2      string            `xml:"ind2,attr"`
+	Subfields []marcXMLSubfield `xml:"subfield"`
+}
+
+type marcXMLRecord struct {
+	XMLName       xml.Name              `xml:"record"`
+	Namespace     string                `xml:"xmlns,attr,omitempty"`
+	Leader        string                `xml:"leader"`
+	ControlFields []marcXMLControlField `xml:"controlfield"`
+	DataFields    []marcXMLDataField    `xml:"datafield"`
+}
+
+// marcXML lays the record out in the MARC 21 slim schema, its leader carries
+// the lengths the record has once encoded in ISO 2709
+func (r Record) marcXML() marcXMLRecord {
+	element := marcXMLRecord{Leader: leader(0, 0)}
+	if encoded, err := r.MarshalBinary(); err == nil {
+		element.Leader = string(encoded[:leaderLength])
+	}
+
+	for _, field := range r.Fields {
+		if field.IsControl() {
+			element.ControlFields = append(element.ControlFields, marcXMLControlField{Tag: field.Tag, Value: field.Value})
+			continue
+		}
+		data := marcXMLDataField{Tag: field.Tag, Ind1: string(field.Ind1), Ind2: string(field.Ind2)}
+		for _, subfield := range field.Subfields {
+			data.Subfields = append(data.Subfields, marcXMLSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+		element.DataFields = append(element.DataFields, data)
+	}
+	return element
+}
+
+// MarshalXML encodes the record as a standalone MARCXML record
+func (r Record) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	element := r.marcXML()
+	element.Namespace = MARCXMLNamespace
+	return e.Encode(element)
+}
+
+// collectionWriter streams elements into a root one, written lazily so
+// nothing reaches w until the first element or Close
+type collectionWriter struct {
+	w         io.Writer
+	e         *xml.Encoder
+	root      xml.StartElement
+	wroteRoot bool
+}
+
+func (cw *collectionWriter) open() error {
+	if cw.wroteRoot {
+		return nil
+	}
+	cw.wroteRoot = true
+	if _, err := io.WriteString(cw.w, xml.Header); err != nil {
+		return err
+	}
+	return cw.e.EncodeToken(cw.root)
+}
+
+func (cw *collectionWriter) write(element interface{}) error {
+	if err := cw.open(); err != nil {
+		return err
+	}
+	return cw.e.Encode(element)
+}
+
+func (cw *collectionWriter) Close() error {
+	if err := cw.open(); err != nil {
+		return err
+	}
+	if err := cw.e.EncodeToken(cw.root.End()); err != nil {
+		return err
+	}
+	return cw.e.Flush()
+}
@@ -0,0 +1,303 @@
+package catalog
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/biblio"
+)
+
+const (
+	FormatXML        = "xml"
+	FormatMARCXML    = biblio.FormatMARCXML
+	FormatMARC       = biblio.FormatMARC
+	FormatDublinCore = biblio.FormatDublinCore
+)
+
+// ExportFormats lists the formats the catalog can be exported in, besides
+// its own csv, jsonl and xml it can be rendered in the formats libraries
+// exchange
+var ExportFormats = append([]string{FormatCSV, FormatJSONL, FormatXML}, biblio.Formats...)
+
+func IsExportFormat(format string) bool {
+	for _, f := range ExportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the media type of an export format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXML:
+		return "application/xml; charset=utf-8"
+	}
+	return biblio.ContentType(format)
+}
+
+// Extension returns the file extension exports in a format are saved with
+func Extension(format string) string {
+	switch format {
+	case FormatMARC:
+		return "mrc"
+	case FormatMARCXML, FormatDublinCore:
+		return format + ".xml"
+	}
+	return format
+}
+
+// Writer writes catalog entries one at a time, Close must be called once
+// they're all written. Nothing reaches the underlying writer until the first
+// entry is written or the writer is closed.
+type Writer interface {
+	Write(domain.CatalogEntry) error
+	Close() error
+}
+
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSONL:
+		return &jsonlWriter{e: json.NewEncoder(w)}, nil
+	case FormatXML:
+		return &xmlWriter{w: w, e: xml.NewEncoder(w)}, nil
+	}
+	if biblio.IsFormat(format) {
+		writer, err := biblio.NewWriter(w, format)
+		if err != nil {
+			return nil, err
+		}
+		return &biblioWriter{w: writer}, nil
+	}
+	return nil, fmt.Errorf("unknown catalog format %q, use one of %s", format, strings.Join(ExportFormats, ", "))
+}
+
+// Export streams every book of the catalog to w. It returns whether anything
+// was written, so callers know if they can still report the error some other
+// way.
+func Export(ctx context.Context, repo ports.BooksRepositoryInterface, w io.Writer, format string) (bool, error) {
+	writer, err := NewWriter(w, format)
+	if err != nil {
+		return false, err
+	}
+
+	written := false
+	if err := repo.ExportBooks(ctx, func(entry domain.CatalogEntry) error {
+		written = true
+		return writer.Write(entry)
+	}); err != nil {
+		return written, fmt.Errorf("exporting books: %s", err.Message())
+	}
+
+	return true, writer.Close()
+}
+
+// CSV exports share the importer's columns, authors being listed as
+// `Last, First` separated by semicolons
+var csvExportColumns = []string{
+	"id",
+	"isbn",
+	"title",
+	"original_release",
+	"description",
+	"short_description",
+	"published",
+	"pages",
+	"price",
+	"status",
+	"seller_id",
+	"publisher_id",
+	"publisher",
+	"author_ids",
+	"authors",
+	"version",
+	"updated_at",
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (cw *csvWriter) header() error {
+	if cw.wroteHeader {
+		return nil
+	}
+	cw.wroteHeader = true
+	return cw.w.Write(csvExportColumns)
+}
+
+func (cw *csvWriter) Write(entry domain.CatalogEntry) error {
+	if err := cw.header(); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(entry.Authors))
+	names := make([]string, len(entry.Authors))
+	for i, author := range entry.Authors {
+		ids[i] = strconv.FormatInt(author.ID, 10)
+		names[i] = author.LastName + ", " + author.FirstName
+	}
+
+	book := entry.Book
+	return cw.w.Write([]string{
+		strconv.FormatInt(book.ID, 10),
+		book.ISBN,
+		book.Title,
+		book.OriginalRelease,
+		book.Description,
+		book.ShortDescription,
+		book.Published,
+		strconv.FormatInt(book.Pages, 10),
+		strconv.FormatInt(book.Price, 10),
+		book.Status,
+		strconv.FormatInt(book.SellerID, 10),
+		strconv.FormatInt(entry.Publisher.ID, 10),
+		entry.Publisher.Name,
+		strings.Join(ids, ";"),
+		strings.Join(names, "; "),
+		strconv.FormatInt(book.Version, 10),
+		book.UpdatedAt,
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.header(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type exportedBook struct {
+	domain.Book
+	Publisher domain.Publisher `json:"publisher"`
+	Authors   []domain.Author  `json:"authors"`
+}
+
+type jsonlWriter struct {
+	e *json.Encoder
+}
+
+func (jw *jsonlWriter) Write(entry domain.CatalogEntry) error {
+	return jw.e.Encode(exportedBook{Book: entry.Book, Publisher: entry.Publisher, Authors: entry.Authors})
+}
+
+func (jw *jsonlWriter) Close() error {
+	return nil
+}
+
+type xmlAuthor struct {
+	ID        int64  `xml:"id,attr"`
+	FirstName string `xml:"first_name"`
+	LastName  string `xml:"last_name"`
+}
+
+type xmlPublisher struct {
+	ID   int64  `xml:"id,attr"`
+	Name string `xml:",chardata"`
+}
+
+type xmlBook struct {
+	XMLName          xml.Name     `xml:"book"`
+	ID               int64        `xml:"id,attr"`
+	ISBN             string       `xml:"isbn,attr,omitempty"`
+	Status           string       `xml:"status,attr"`
+	Version          int64        `xml:"version,attr"`
+	Title            string       `xml:"title"`
+	OriginalRelease  string       `xml:"original_release"`
+	Description      string       `xml:"description"`
+	ShortDescription string       `xml:"short_description"`
+	Published        string       `xml:"published"`
+	Pages            int64        `xml:"pages"`
+	Price            int64        `xml:"price"`
+	SellerID         int64        `xml:"seller_id"`
+	Publisher        xmlPublisher `xml:"publisher"`
+	Authors          []xmlAuthor  `xml:"authors>author"`
+	UpdatedAt        string       `xml:"updated_at"`
+}
+
+var xmlRoot = xml.StartElement{Name: xml.Name{Local: "books"}}
+
+type xmlWriter struct {
+	w         io.Writer
+	e         *xml.Encoder
+	wroteRoot bool
+}
+
+func (xw *xmlWriter) root() error {
+	if xw.wroteRoot {
+		return nil
+	}
+	xw.wroteRoot = true
+	if _, err := io.WriteString(xw.w, xml.Header); err != nil {
+		return err
+	}
+	return xw.e.EncodeToken(xmlRoot)
+}
+
+func (xw *xmlWriter) Write(entry domain.CatalogEntry) error {
+	if err := xw.root(); err != nil {
+		return err
+	}
+
+	book := entry.Book
+	element := xmlBook{
+		ID:               book.ID,
+		ISBN:             book.ISBN,
+		Status:           book.Status,
+		Version:          book.Version,
+		Title:            book.Title,
+		OriginalRelease:  book.OriginalRelease,
+		Description:      book.Description,
+		ShortDescription: book.ShortDescription,
+		Published:        book.Published,
+		Pages:            book.Pages,
+		Price:            book.Price,
+		SellerID:         book.SellerID,
+		Publisher:        xmlPublisher{ID: entry.Publisher.ID, Name: entry.Publisher.Name},
+		UpdatedAt:        book.UpdatedAt,
+	}
+	for _, author := range entry.Authors {
+		element.Authors = append(element.Authors, xmlAuthor{ID: author.ID, FirstName: author.FirstName, LastName: author.LastName})
+	}
+
+	return xw.e.Encode(element)
+}
+
+func (xw *xmlWriter) Close() error {
+	if err := xw.root(); err != nil {
+		return err
+	}
+	if err := xw.e.EncodeToken(xmlRoot.End()); err != nil {
+		return err
+	}
+	return xw.e.Flush()
+}
+
+type biblioWriter struct {
+	w biblio.Writer
+}
+
+func (bw *biblioWriter) Write(entry domain.CatalogEntry) error {
+	return bw.w.Write(domain.BookDenormalized{Book: entry.Book, Publisher: entry.Publisher, Authors: entry.Authors})
+}
+
+func (bw *biblioWriter) Close() error {
+	return bw.w.Close()
+}
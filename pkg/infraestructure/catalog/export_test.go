@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEntries = []domain.CatalogEntry{
+	{
+		Book:      domain.Book{ID: 1, Title: "Ficciones", OriginalRelease: "1944-01-01", Published: "1944-01-01", PublisherID: 2, Pages: 174, Price: 1999, Status: "published", Version: 1},
+		Publisher: domain.Publisher{ID: 2, Name: "Sur"},
+		Authors:   []domain.Author{{ID: 3, FirstName: "Jorge Luis", LastName: "Borges"}},
+	},
+	{
+		Book:      domain.Book{ID: 4, Title: "Ubik", OriginalRelease: "1969-05-01", Published: "1969-05-01", PublisherID: 5, Status: "draft", Version: 2},
+		Publisher: domain.Publisher{ID: 5, Name: "Doubleday"},
+		Authors:   []domain.Author{{ID: 6, FirstName: "Philip K.", LastName: "Dick"}},
+	},
+}
+
+type exportRepo struct {
+	fakeRepo
+	err error
+}
+
+func (r *exportRepo) ExportBooks(ctx context.Context, fn func(domain.CatalogEntry) error) rest_errors.RestErr {
+	if r.err != nil {
+		return rest_errors.NewInternalServerError(r.err.Error())
+	}
+	for _, entry := range testEntries {
+		if err := fn(entry); err != nil {
+			return rest_errors.NewInternalServerError(err.Error())
+		}
+	}
+	return nil
+}
+
+func TestExportCSVIsImportable(t *testing.T) {
+	var out bytes.Buffer
+	written, err := Export(context.Background(), &exportRepo{}, &out, FormatCSV)
+	assert.Nil(t, err)
+	assert.True(t, written)
+
+	r, err := NewReader(&out, FormatCSV)
+	assert.Nil(t, err)
+
+	record, line, err := r.Read()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, line)
+	assert.Empty(t, record.Validate())
+	assert.EqualValues(t, "Ficciones", record.Title)
+	assert.EqualValues(t, "Sur", record.Publisher.Name)
+	assert.EqualValues(t, []RecordAuthor{{FirstName: "Jorge Luis", LastName: "Borges"}}, record.Authors)
+	assert.EqualValues(t, 1999, record.Price)
+}
+
+func TestExportXML(t *testing.T) {
+	var out bytes.Buffer
+	_, err := Export(context.Background(), &exportRepo{}, &out, FormatXML)
+	assert.Nil(t, err)
+
+	var doc struct {
+		Books []xmlBook `xml:"book"`
+	}
+	assert.Nil(t, xml.Unmarshal(out.Bytes(), &doc))
+	assert.Len(t, doc.Books, 2)
+	assert.EqualValues(t, "Doubleday", doc.Books[1].Publisher.Name)
+	assert.EqualValues(t, "Dick", doc.Books[1].Authors[0].LastName)
+	assert.EqualValues(t, "draft", doc.Books[1].Status)
+}
+
+func TestExportJSONL(t *testing.T) {
+	var out bytes.Buffer
+	_, err := Export(context.Background(), &exportRepo{}, &out, FormatJSONL)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"publisher":{"id":2,"name":"Sur"}`)
+}
+
+func TestExportFailsBeforeWriting(t *testing.T) {
+	var out bytes.Buffer
+	written, err := Export(context.Background(), &exportRepo{err: errors.New("connection refused")}, &out, FormatXML)
+	assert.NotNil(t, err)
+	assert.False(t, written)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,250 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+const DefaultBatchSize = 100
+
+// Importer loads catalog records into the repository, books are saved in
+// batches and the last line of every batch is checkpointed in the batch's
+// transaction, so an interrupted import resumes right after the last batch
+// saved.
+//
+// Publishers and authors are resolved by name and created when missing, they
+// aren't part of the batches but since they're resolved again on resume no
+// duplicates are created.
+type Importer struct {
+	Repo ports.BooksRepositoryInterface
+	// ActorID is recorded as the seller of the books and in the audit log
+	ActorID int64
+	// Status is the status books are created in
+	Status    string
+	BatchSize int
+	// DryRun validates the file and resolves names without writing anything
+	DryRun bool
+	// Checkpoint is the name the import's progress is tracked under in the
+	// database, empty to not keep track of it
+	Checkpoint string
+
+	publishers map[string]int64
+	authors    map[RecordAuthor]int64
+	batch      []domain.Book
+	batchEnd   int
+}
+
+// Report sums up an import, or what it would do when running dry
+type Report struct {
+	Read     int
+	Skipped  int
+	Imported int
+	// CreatedPublishers and CreatedAuthors list the names that didn't resolve
+	// to an existing record
+	CreatedPublishers []string
+	CreatedAuthors    []string
+	Errors            []*LineError
+	// LastLine is the last committed line
+	LastLine int
+}
+
+// Run imports every record of r. Out of a dry run it stops at the first
+// invalid record, after committing the ones before it, so it can be fixed and
+// the import resumed.
+func (im *Importer) Run(ctx context.Context, r Reader) (*Report, error) {
+	if im.BatchSize <= 0 {
+		im.BatchSize = DefaultBatchSize
+	}
+	if im.Status == "" {
+		im.Status = domain.StatusPendingReview
+	}
+	if !domain.IsValidStatus(im.Status) {
+		return nil, fmt.Errorf("unknown status %q", im.Status)
+	}
+	im.publishers = map[string]int64{}
+	im.authors = map[RecordAuthor]int64{}
+	im.batch = nil
+
+	report := &Report{}
+	resumeAfter, err := im.loadCheckpoint(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.LastLine = resumeAfter
+
+	for {
+		record, line, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			lineErr, ok := err.(*LineError)
+			if !ok {
+				return report, err
+			}
+			if line <= resumeAfter {
+				continue
+			}
+			report.Read++
+			if err := im.reject(ctx, report, lineErr); err != nil {
+				return report, err
+			}
+			continue
+		}
+
+		report.Read++
+		if line <= resumeAfter {
+			report.Skipped++
+			continue
+		}
+
+		if problems := record.Validate(); len(problems) > 0 {
+			if err := im.reject(ctx, report, &LineError{Line: line, Err: strings.Join(problems, ", ")}); err != nil {
+				return report, err
+			}
+			continue
+		}
+
+		book, err := im.resolve(ctx, record, report)
+		if err != nil {
+			if lineErr, ok := err.(*LineError); ok {
+				lineErr.Line = line
+				if err := im.reject(ctx, report, lineErr); err != nil {
+					return report, err
+				}
+				continue
+			}
+			return report, err
+		}
+
+		if im.DryRun {
+			report.Imported++
+			continue
+		}
+
+		im.batch = append(im.batch, book)
+		im.batchEnd = line
+		if len(im.batch) >= im.BatchSize {
+			if err := im.flush(ctx, report); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := im.flush(ctx, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// reject records a problem with a record, which ends the import unless it's
+// a dry run
+func (im *Importer) reject(ctx context.Context, report *Report, lineErr *LineError) error {
+	report.Errors = append(report.Errors, lineErr)
+	if im.DryRun {
+		return nil
+	}
+	if err := im.flush(ctx, report); err != nil {
+		return err
+	}
+	return lineErr
+}
+
+func (im *Importer) flush(ctx context.Context, report *Report) error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+
+	var err rest_errors.RestErr
+	if im.Checkpoint == "" {
+		err = im.Repo.SaveBooks(ctx, im.ActorID, im.batch)
+	} else {
+		err = im.Repo.SaveImportBatch(ctx, im.ActorID, im.batch, domain.ImportCheckpoint{Name: im.Checkpoint, Line: im.batchEnd})
+	}
+	if err != nil {
+		return fmt.Errorf("saving the books up to line %d: %s", im.batchEnd, err.Message())
+	}
+	report.Imported += len(im.batch)
+	report.LastLine = im.batchEnd
+	im.batch = im.batch[:0]
+	return nil
+}
+
+// resolve turns the record into a book, creating its publisher and authors if
+// they don't exist. Dry runs only check they could be created.
+func (im *Importer) resolve(ctx context.Context, record Record, report *Report) (domain.Book, error) {
+	publisherID, ok := im.publishers[record.Publisher.Name]
+	if !ok {
+		publisher, err := im.Repo.GetPublisherByName(ctx, record.Publisher.Name)
+		switch {
+		case err == nil:
+			publisherID = publisher.ID
+		case err.Status() != http.StatusNotFound:
+			return domain.Book{}, fmt.Errorf("looking up publisher %q: %s", record.Publisher.Name, err.Message())
+		case record.Publisher.Founded == "":
+			return domain.Book{}, &LineError{Err: fmt.Sprintf("publisher %q doesn't exist, its founded date is required to create it", record.Publisher.Name)}
+		default:
+			created := domain.Publisher{Name: record.Publisher.Name, Founded: record.Publisher.Founded}
+			if !im.DryRun {
+				if err := im.Repo.SavePublisher(ctx, im.ActorID, &created); err != nil {
+					return domain.Book{}, fmt.Errorf("creating publisher %q: %s", created.Name, err.Message())
+				}
+			}
+			publisherID = created.ID
+			report.CreatedPublishers = append(report.CreatedPublishers, created.Name)
+		}
+		im.publishers[record.Publisher.Name] = publisherID
+	}
+
+	authorIDs := make([]int64, 0, len(record.Authors))
+	for _, author := range record.Authors {
+		key := RecordAuthor{FirstName: author.FirstName, LastName: author.LastName}
+		authorID, ok := im.authors[key]
+		if !ok {
+			existing, err := im.Repo.GetAuthorByName(ctx, author.FirstName, author.LastName)
+			switch {
+			case err == nil:
+				authorID = existing.ID
+			case err.Status() != http.StatusNotFound:
+				return domain.Book{}, fmt.Errorf("looking up author %q: %s", author.String(), err.Message())
+			case author.Birthday == "":
+				return domain.Book{}, &LineError{Err: fmt.Sprintf("author %q doesn't exist, their birthday is required to create them", author.String())}
+			default:
+				created := domain.Author{FirstName: author.FirstName, LastName: author.LastName, Birthday: author.Birthday}
+				if !im.DryRun {
+					if err := im.Repo.SaveAuthor(ctx, im.ActorID, &created); err != nil {
+						return domain.Book{}, fmt.Errorf("creating author %q: %s", author.String(), err.Message())
+					}
+				}
+				authorID = created.ID
+				report.CreatedAuthors = append(report.CreatedAuthors, author.String())
+			}
+			im.authors[key] = authorID
+		}
+		authorIDs = append(authorIDs, authorID)
+	}
+
+	book := record.Book(publisherID, authorIDs)
+	book.SellerID = im.ActorID
+	book.Status = im.Status
+	return book, nil
+}
+
+func (im *Importer) loadCheckpoint(ctx context.Context) (int, error) {
+	if im.Checkpoint == "" {
+		return 0, nil
+	}
+
+	line, err := im.Repo.GetImportCheckpoint(ctx, im.Checkpoint)
+	if err != nil {
+		return 0, fmt.Errorf("reading checkpoint %q: %s", im.Checkpoint, err.Message())
+	}
+	return line, nil
+}
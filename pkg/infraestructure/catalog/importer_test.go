@@ -0,0 +1,161 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo implements the part of the repository the importer uses, calling
+// anything else panics
+type fakeRepo struct {
+	ports.BooksRepositoryInterface
+
+	publishers  map[string]int64
+	authors     map[string]int64
+	saved       []domain.Book
+	checkpoints map[string]int
+	nextID      int64
+	failAfter   int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		publishers:  map[string]int64{"Sur": 1},
+		authors:     map[string]int64{"Borges, Jorge Luis": 1},
+		checkpoints: map[string]int{},
+		nextID:      100,
+		failAfter:   -1,
+	}
+}
+
+func (f *fakeRepo) GetPublisherByName(ctx context.Context, name string) (*domain.Publisher, rest_errors.RestErr) {
+	if id, ok := f.publishers[name]; ok {
+		return &domain.Publisher{ID: id, Name: name}, nil
+	}
+	return nil, rest_errors.NewNotFoundError("publisher not found")
+}
+
+func (f *fakeRepo) GetAuthorByName(ctx context.Context, firstName string, lastName string) (*domain.Author, rest_errors.RestErr) {
+	if id, ok := f.authors[lastName+", "+firstName]; ok {
+		return &domain.Author{ID: id, FirstName: firstName, LastName: lastName}, nil
+	}
+	return nil, rest_errors.NewNotFoundError("author not found")
+}
+
+func (f *fakeRepo) SavePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) rest_errors.RestErr {
+	f.nextID++
+	publisher.ID = f.nextID
+	f.publishers[publisher.Name] = publisher.ID
+	return nil
+}
+
+func (f *fakeRepo) SaveAuthor(ctx context.Context, actorID int64, author *domain.Author) rest_errors.RestErr {
+	f.nextID++
+	author.ID = f.nextID
+	f.authors[author.LastName+", "+author.FirstName] = author.ID
+	return nil
+}
+
+func (f *fakeRepo) SaveBooks(ctx context.Context, actorID int64, books []domain.Book) rest_errors.RestErr {
+	if f.failAfter >= 0 && len(f.saved)+len(books) > f.failAfter {
+		return rest_errors.NewInternalServerError("connection lost")
+	}
+	f.saved = append(f.saved, books...)
+	return nil
+}
+
+// SaveImportBatch saves the books and the checkpoint together, as the
+// repository does in a transaction
+func (f *fakeRepo) SaveImportBatch(ctx context.Context, actorID int64, books []domain.Book, checkpoint domain.ImportCheckpoint) rest_errors.RestErr {
+	if err := f.SaveBooks(ctx, actorID, books); err != nil {
+		return err
+	}
+	f.checkpoints[checkpoint.Name] = checkpoint.Line
+	return nil
+}
+
+func (f *fakeRepo) GetImportCheckpoint(ctx context.Context, name string) (int, rest_errors.RestErr) {
+	return f.checkpoints[name], nil
+}
+
+const testCatalog = `{"title":"Ficciones","original_release":"1944-01-01","published":"1944-01-01","publisher":{"name":"Sur"},"authors":[{"first_name":"Jorge Luis","last_name":"Borges"}]}
+{"title":"Ubik","original_release":"1969-05-01","published":"1969-05-01","publisher":{"name":"Doubleday","founded":"1897-01-01"},"authors":[{"first_name":"Philip K.","last_name":"Dick","birthday":"1928-12-16"}]}
+{"title":"Valis","original_release":"1981-02-01","published":"1981-02-01","publisher":{"name":"Bantam"},"authors":[{"first_name":"Philip K.","last_name":"Dick"}]}
+{"title":"","original_release":"1962-01-01","published":"1962-01-01","publisher":{"name":"Sur"},"authors":[]}
+`
+
+func reader(t *testing.T, content string) Reader {
+	r, err := NewReader(strings.NewReader(content), FormatJSONL)
+	assert.Nil(t, err)
+	return r
+}
+
+func TestImporterDryRun(t *testing.T) {
+	repo := newFakeRepo()
+	importer := Importer{Repo: repo, ActorID: 7, DryRun: true}
+
+	report, err := importer.Run(context.Background(), reader(t, testCatalog))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4, report.Read)
+	assert.EqualValues(t, 2, report.Imported)
+	assert.EqualValues(t, []string{"Doubleday"}, report.CreatedPublishers)
+	assert.EqualValues(t, []string{"Dick, Philip K."}, report.CreatedAuthors)
+
+	assert.Len(t, report.Errors, 2)
+	assert.EqualValues(t, 3, report.Errors[0].Line)
+	assert.Contains(t, report.Errors[0].Err, "Bantam")
+	assert.EqualValues(t, 4, report.Errors[1].Line)
+	assert.Contains(t, report.Errors[1].Err, "title is required")
+
+	assert.Empty(t, repo.saved)
+	assert.NotContains(t, repo.publishers, "Doubleday")
+}
+
+func TestImporterResumes(t *testing.T) {
+	repo := newFakeRepo()
+	repo.failAfter = 1
+
+	importer := Importer{Repo: repo, ActorID: 7, BatchSize: 1, Checkpoint: "/imports/catalog.jsonl"}
+
+	valid := strings.Join(strings.Split(testCatalog, "\n")[:2], "\n")
+
+	report, err := importer.Run(context.Background(), reader(t, valid))
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 1, report.Imported)
+	assert.EqualValues(t, 1, report.LastLine)
+
+	assert.EqualValues(t, 1, repo.checkpoints["/imports/catalog.jsonl"])
+
+	repo.failAfter = -1
+	report, err = importer.Run(context.Background(), reader(t, valid))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, report.Skipped)
+	assert.EqualValues(t, 1, report.Imported)
+	assert.EqualValues(t, 2, report.LastLine)
+	assert.EqualValues(t, 2, repo.checkpoints["/imports/catalog.jsonl"])
+
+	assert.Len(t, repo.saved, 2)
+	assert.EqualValues(t, "Ficciones", repo.saved[0].Title)
+	assert.EqualValues(t, "Ubik", repo.saved[1].Title)
+	assert.EqualValues(t, domain.StatusPendingReview, repo.saved[1].Status)
+	assert.EqualValues(t, 7, repo.saved[1].SellerID)
+	assert.EqualValues(t, []int64{repo.authors["Dick, Philip K."]}, repo.saved[1].AuthorID)
+}
+
+func TestImporterStopsAtInvalidRecord(t *testing.T) {
+	repo := newFakeRepo()
+	importer := Importer{Repo: repo, ActorID: 7, Checkpoint: "/imports/catalog.jsonl"}
+
+	report, err := importer.Run(context.Background(), reader(t, testCatalog))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+	assert.EqualValues(t, 2, report.Imported)
+	assert.EqualValues(t, 2, report.LastLine)
+	assert.Len(t, repo.saved, 2)
+}
@@ -0,0 +1,211 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// FormatOf guesses the format of a catalog file from its extension
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".mrc":
+		return FormatMARC
+	case ".xml":
+		for _, format := range []string{FormatMARCXML, FormatDublinCore} {
+			if strings.HasSuffix(strings.ToLower(path), "."+Extension(format)) {
+				return format
+			}
+		}
+		return FormatXML
+	}
+	return ""
+}
+
+// Reader reads catalog records one at a time along with the line they start
+// at, it returns io.EOF once the file is over. A malformed record is reported
+// with a *LineError and doesn't prevent reading the following ones.
+type Reader interface {
+	Read() (Record, int, error)
+}
+
+// LineError is a problem with the record starting at Line
+type LineError struct {
+	Line int
+	Err  string
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return newJSONLReader(r), nil
+	}
+	return nil, fmt.Errorf("unknown catalog format %q, use csv or jsonl", format)
+}
+
+// CSV files have a header naming their columns, in any order. Authors are
+// listed as `Last, First` separated by semicolons, and their optional
+// birthdays in the same order in author_birthdays.
+var csvRequiredColumns = []string{"title", "publisher", "authors"}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %s column", name)
+		}
+	}
+
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (Record, int, error) {
+	fields, err := r.r.Read()
+	if err == io.EOF {
+		return Record{}, 0, io.EOF
+	}
+	if err != nil {
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			return Record{}, parseErr.StartLine, &LineError{Line: parseErr.StartLine, Err: parseErr.Err.Error()}
+		}
+		return Record{}, 0, err
+	}
+	line, _ := r.r.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	record := Record{
+		ISBN:             field("isbn"),
+		Title:            field("title"),
+		OriginalRelease:  field("original_release"),
+		Description:      field("description"),
+		ShortDescription: field("short_description"),
+		Published:        field("published"),
+		Publisher: RecordPublisher{
+			Name:    field("publisher"),
+			Founded: field("publisher_founded"),
+		},
+	}
+
+	if record.Pages, err = parseInt(field("pages")); err != nil {
+		return record, line, &LineError{Line: line, Err: "pages must be a number"}
+	}
+	if record.Price, err = parseInt(field("price")); err != nil {
+		return record, line, &LineError{Line: line, Err: "price must be a number of cents"}
+	}
+
+	birthdays := splitList(field("author_birthdays"))
+	for i, name := range splitList(field("authors")) {
+		author := RecordAuthor{}
+		if comma := strings.Index(name, ","); comma >= 0 {
+			author.LastName = strings.TrimSpace(name[:comma])
+			author.FirstName = strings.TrimSpace(name[comma+1:])
+		} else {
+			author.LastName = name
+		}
+		if i < len(birthdays) {
+			author.Birthday = birthdays[i]
+		}
+		record.Authors = append(record.Authors, author)
+	}
+
+	return record, line, nil
+}
+
+func parseInt(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	items := strings.Split(value, ";")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// maxJSONLLine bounds the length of a single record in a JSON Lines file
+const maxJSONLLine = 1 << 20
+
+type jsonlReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), maxJSONLLine)
+	return &jsonlReader{s: s}
+}
+
+func (r *jsonlReader) Read() (Record, int, error) {
+	for r.s.Scan() {
+		r.line++
+		raw := bytes.TrimSpace(r.s.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var record Record
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			return record, r.line, &LineError{Line: r.line, Err: err.Error()}
+		}
+		return record, r.line, nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return Record{}, r.line + 1, err
+	}
+	return Record{}, 0, io.EOF
+}
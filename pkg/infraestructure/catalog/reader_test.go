@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVReader(t *testing.T) {
+	file := `title,publisher,authors,author_birthdays,published,original_release,pages,price
+Ficciones,Sur,"Borges, Jorge Luis",1899-08-24,1944-01-01,1944-01-01,174,1999
+"Flow my tears,
+the policeman said",DAW,"Dick, Philip K.; Borges, Jorge Luis",,1974-02-01,1974-02-01,231,abc
+`
+	r, err := NewReader(strings.NewReader(file), FormatCSV)
+	assert.Nil(t, err)
+
+	record, line, err := r.Read()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, line)
+	assert.EqualValues(t, "Ficciones", record.Title)
+	assert.EqualValues(t, "Sur", record.Publisher.Name)
+	assert.EqualValues(t, []RecordAuthor{{FirstName: "Jorge Luis", LastName: "Borges", Birthday: "1899-08-24"}}, record.Authors)
+	assert.EqualValues(t, 174, record.Pages)
+	assert.EqualValues(t, 1999, record.Price)
+
+	record, line, err = r.Read()
+	assert.EqualValues(t, 3, line)
+	assert.IsType(t, &LineError{}, err)
+	assert.Len(t, record.Authors, 0)
+
+	_, _, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVReaderMissingColumns(t *testing.T) {
+	_, err := NewReader(strings.NewReader("title,authors\n"), FormatCSV)
+	assert.NotNil(t, err)
+}
+
+func TestJSONLReader(t *testing.T) {
+	file := `{"title":"Ficciones","publisher":{"name":"Sur"},"authors":[{"first_name":"Jorge Luis","last_name":"Borges"}]}
+
+{"title":"Ubik","pubisher":{"name":"Doubleday"}}
+`
+	r, err := NewReader(strings.NewReader(file), FormatJSONL)
+	assert.Nil(t, err)
+
+	record, line, err := r.Read()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, line)
+	assert.EqualValues(t, "Borges", record.Authors[0].LastName)
+
+	_, line, err = r.Read()
+	assert.EqualValues(t, 3, line)
+	assert.IsType(t, &LineError{}, err)
+
+	_, _, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.EqualValues(t, FormatCSV, FormatOf("catalog.CSV"))
+	assert.EqualValues(t, FormatJSONL, FormatOf("/tmp/catalog.ndjson"))
+	assert.EqualValues(t, FormatXML, FormatOf("catalog.xml"))
+	assert.EqualValues(t, "", FormatOf("catalog.txt"))
+}
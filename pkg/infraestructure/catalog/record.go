@@ -0,0 +1,115 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+)
+
+// DateLayout is the layout of the DATE columns catalog files carry
+const DateLayout = domain.DateLayout
+
+// Record is a book as it's laid out in a catalog file, its publisher and
+// authors are referenced by name rather than by id
+type Record struct {
+	ISBN             string          `json:"isbn,omitempty"`
+	Title            string          `json:"title"`
+	OriginalRelease  string          `json:"original_release"`
+	Description      string          `json:"description"`
+	ShortDescription string          `json:"short_description"`
+	Published        string          `json:"published"`
+	Pages            int64           `json:"pages"`
+	Price            int64           `json:"price"`
+	Publisher        RecordPublisher `json:"publisher"`
+	Authors          []RecordAuthor  `json:"authors"`
+}
+
+// RecordPublisher is looked up by name, Founded is only needed when the
+// publisher doesn't exist yet and has to be created
+type RecordPublisher struct {
+	Name    string `json:"name"`
+	Founded string `json:"founded,omitempty"`
+}
+
+// RecordAuthor is looked up by name, Birthday is only needed when the author
+// doesn't exist yet and has to be created
+type RecordAuthor struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Birthday  string `json:"birthday,omitempty"`
+}
+
+func (a RecordAuthor) String() string {
+	return a.LastName + ", " + a.FirstName
+}
+
+// Validate returns every problem found in the record, nil if there's none
+func (r Record) Validate() []string {
+	var problems []string
+	if r.ISBN != "" {
+		if _, err := domain.NormalizeISBN(r.ISBN); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if strings.TrimSpace(r.Title) == "" {
+		problems = append(problems, "title is required")
+	}
+	if !isDate(r.OriginalRelease) {
+		problems = append(problems, "original_release must be a YYYY-MM-DD date")
+	}
+	if !isDate(r.Published) {
+		problems = append(problems, "published must be a YYYY-MM-DD date")
+	}
+	if r.Pages < 0 {
+		problems = append(problems, "pages can't be negative")
+	}
+	if r.Price < 0 {
+		problems = append(problems, "price can't be negative")
+	}
+
+	if strings.TrimSpace(r.Publisher.Name) == "" {
+		problems = append(problems, "publisher is required")
+	}
+	if r.Publisher.Founded != "" && !isDate(r.Publisher.Founded) {
+		problems = append(problems, "publisher founded must be a YYYY-MM-DD date")
+	}
+
+	if len(r.Authors) == 0 {
+		problems = append(problems, "at least one author is required")
+	}
+	for _, author := range r.Authors {
+		if strings.TrimSpace(author.FirstName) == "" || strings.TrimSpace(author.LastName) == "" {
+			problems = append(problems, fmt.Sprintf("author %q needs both a first and a last name", author.String()))
+		}
+		if author.Birthday != "" && !isDate(author.Birthday) {
+			problems = append(problems, fmt.Sprintf("author %q birthday must be a YYYY-MM-DD date", author.String()))
+		}
+	}
+
+	return problems
+}
+
+// Book returns the book the record describes, publisherID and authorIDs being
+// the ids its publisher and authors resolved to
+func (r Record) Book(publisherID int64, authorIDs []int64) domain.Book {
+	isbn, _ := domain.NormalizeISBN(r.ISBN)
+	return domain.Book{
+		ISBN:             isbn,
+		Title:            r.Title,
+		OriginalRelease:  r.OriginalRelease,
+		Description:      r.Description,
+		ShortDescription: r.ShortDescription,
+		Published:        r.Published,
+		PublisherID:      publisherID,
+		Pages:            r.Pages,
+		AuthorID:         authorIDs,
+		Price:            r.Price,
+	}
+}
+
+func isDate(value string) bool {
+	_, err := time.Parse(DateLayout, value)
+	return err == nil
+}
@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// DialAuth opens the connection access tokens are validated through, without
+// waiting for the users service to be up. The caller owns it: it's the one
+// readiness probes and the one to close once no request is left. Validations
+// carry the trace of the request they're made for to the users service.
+func DialAuth(cfg config.OAuth) (*grpc.ClientConn, error) {
+	return grpc.Dial(cfg.Address,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
+}
+
+// Reachable waits for conn to be ready, failing once ctx is done
+func Reachable(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection to %s is %s", conn.Target(), state)
+		}
+	}
+}
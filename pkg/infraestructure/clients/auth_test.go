@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDialAuthPropagatesTraces(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	// stands in for the users service, answering every method with the
+	// traceparent it was called with
+	traceparents := make(chan []string, 1)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		traceparents <- md.Get("traceparent")
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := DialAuth(config.OAuth{Address: lis.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	err = conn.Invoke(ctx, "/oauth.OauthService/ValidateToken", &emptypb.Empty{}, &emptypb.Empty{})
+	assert.Nil(t, err)
+	if got := <-traceparents; assert.Len(t, got, 1) {
+		assert.Contains(t, got[0], traceID.String())
+	}
+}
@@ -1,30 +1,94 @@
 package clients
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
+	"log"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/tracing"
+	"github.com/go-sql-driver/mysql"
 )
 
-func ConnectDB() *sql.DB {
-	dataSourceName := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8",
-		os.Getenv("MYSQL_USER"),
-		os.Getenv("MYSQL_PASSWORD"),
-		os.Getenv("MYSQL_ADDRESS"),
-		os.Getenv("MYSQL_DB"),
-	)
+// Retries back off exponentially from initialBackoff up to maxBackoff
+var (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 10 * time.Second
+)
 
-	db, err := sql.Open("mysql", dataSourceName)
+// ConnectDB opens the pool and waits for MySQL to accept connections, retrying
+// until cfg.ConnectDeadline, so the service survives starting before the
+// database does
+func ConnectDB(ctx context.Context, cfg config.MySQL) (*sql.DB, error) {
+	log.Printf("connecting to mysql: %s", describe(cfg))
+
+	connector, err := mysql.NewConnector(driverConfig(cfg))
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
+	db := sql.OpenDB(tracing.Connector(connector))
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectDeadline)
+	defer cancel()
 
-	if err = db.Ping(); err != nil {
-		panic(err)
+	if err := waitFor(ctx, db); err != nil {
+		db.Close()
+		return nil, err
 	}
-	fmt.Println("databese succesfully configured")
+	log.Println("database successfully configured")
 
-	return db
+	return db, nil
+}
+
+func driverConfig(cfg config.MySQL) *mysql.Config {
+	dsn := mysql.NewConfig()
+	dsn.User = cfg.User
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = cfg.Address
+	dsn.DBName = cfg.Database
+	dsn.Params = map[string]string{"charset": "utf8"}
+	dsn.TLSConfig = cfg.TLS
+	dsn.Timeout = cfg.DialTimeout
+	dsn.ReadTimeout = cfg.ReadTimeout
+	dsn.WriteTimeout = cfg.WriteTimeout
+
+	return dsn
+}
+
+// describe lists the effective settings, leaving the password out
+func describe(cfg config.MySQL) string {
+	return fmt.Sprintf(
+		"user=%s address=%s database=%s tls=%s dial_timeout=%s read_timeout=%s write_timeout=%s "+
+			"max_open_conns=%d max_idle_conns=%d conn_max_lifetime=%s connect_deadline=%s",
+		cfg.User, cfg.Address, cfg.Database, cfg.TLS, cfg.DialTimeout, cfg.ReadTimeout, cfg.WriteTimeout,
+		cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime, cfg.ConnectDeadline,
+	)
+}
+
+// waitFor pings db until it answers or ctx is done
+func waitFor(ctx context.Context, db *sql.DB) error {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("mysql isn't reachable yet (attempt %d): %s, retrying in %s", attempt, err, backoff)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("giving up on mysql after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
 }
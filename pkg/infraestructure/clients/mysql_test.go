@@ -0,0 +1,65 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var cfg = config.MySQL{
+	User:            "books",
+	Password:        "s3cret",
+	Address:         "mysql:3306",
+	Database:        "books_db",
+	TLS:             "skip-verify",
+	DialTimeout:     5 * time.Second,
+	ReadTimeout:     30 * time.Second,
+	WriteTimeout:    30 * time.Second,
+	MaxOpenConns:    25,
+	MaxIdleConns:    25,
+	ConnMaxLifetime: 5 * time.Minute,
+	ConnectDeadline: time.Minute,
+}
+
+func TestDataSourceName(t *testing.T) {
+	assert.EqualValues(t,
+		"books:s3cret@tcp(mysql:3306)/books_db?readTimeout=30s&timeout=5s&tls=skip-verify&writeTimeout=30s&charset=utf8",
+		driverConfig(cfg).FormatDSN(),
+	)
+	assert.NotContains(t, describe(cfg), cfg.Password)
+}
+
+func TestWaitFor(t *testing.T) {
+	initialBackoff, maxBackoff = time.Millisecond, 2*time.Millisecond
+
+	t.Run("Retries", func(t *testing.T) {
+		db, mock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectPing()
+
+		assert.Nil(t, waitFor(context.Background(), db))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		db, mock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		defer db.Close()
+		for i := 0; i < 100; i++ {
+			mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := waitFor(ctx, db)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "giving up on mysql")
+	})
+}
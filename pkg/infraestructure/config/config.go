@@ -0,0 +1,528 @@
+// Package config loads the service settings. Every setting has a default,
+// which an optional dotenv file, the environment and command-line flags
+// override, in that order.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	HTTP    HTTP
+	GRPC    GRPC
+	MySQL   MySQL
+	Migrate Migrate
+	OAuth   OAuth
+	Log     Log
+	Tracing Tracing
+}
+
+type HTTP struct {
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	ShutdownTimeout   time.Duration
+	// DrainDelay is how long the service keeps serving while failing
+	// readiness before it stops accepting requests, for load balancers to
+	// notice it's going away
+	DrainDelay time.Duration
+	// ReadinessTimeout bounds each dependency check of /readyz
+	ReadinessTimeout time.Duration
+	// TrustedProxies are the IPs and CIDRs whose X-Forwarded-For and
+	// X-Real-IP headers are believed, callers are told apart by IP so
+	// trusting anyone else would let them pick their own
+	TrustedProxies []string
+	RateLimits     RateLimits
+	CORS           CORS
+	// HSTSMaxAge is how long browsers must only reach the API over HTTPS, 0
+	// to not ask them to, as when it's served over plain HTTP
+	HSTSMaxAge time.Duration
+}
+
+// CORS lets browser apps served from other origins call the API
+type CORS struct {
+	// AllowedOrigins are the origins allowed, * allowing any, none at all
+	// turning CORS off
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the answer to a preflight request
+	MaxAge time.Duration
+}
+
+// RateLimits are the limits of each group of routes. Read covers the public
+// catalog and GraphQL, Write the authenticated routes and Bulk the imports
+// and exports, which cost the most.
+type RateLimits struct {
+	Read  RateLimit
+	Write RateLimit
+	Bulk  RateLimit
+}
+
+// RateLimit is applied per IP to anonymous callers, and to callers whose
+// token couldn't be validated, and per user to the others
+type RateLimit struct {
+	Anonymous Rate
+	User      Rate
+}
+
+// Rate lets Requests through every Period, in bursts of up to Requests. A
+// zero Rate doesn't limit anything.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+type GRPC struct {
+	Addr string
+}
+
+type MySQL struct {
+	User     string
+	Password string
+	Address  string
+	Database string
+
+	// TLS is the driver's tls parameter, false, true, skip-verify or
+	// preferred
+	TLS          string
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// ConnectDeadline bounds how long startup waits for MySQL to accept
+	// connections
+	ConnectDeadline time.Duration
+
+	QueryTimeouts QueryTimeouts
+}
+
+// QueryTimeouts bound how long each kind of repository operation may run, 0
+// meaning it runs for as long as its caller waits
+type QueryTimeouts struct {
+	// Read bounds lookups and listings
+	Read time.Duration
+	// Write bounds the transactions that save or update records
+	Write time.Duration
+	// Export bounds streaming the whole catalog
+	Export time.Duration
+}
+
+// Migrate is how the schema migrations embedded in the binary are applied
+type Migrate struct {
+	// OnStart applies the pending migrations before the service starts
+	// serving, instead of leaving it to the migrate subcommand
+	OnStart bool
+	// LockTimeout is how long a run waits for another one to finish, runners
+	// take turns so replicas starting together don't race
+	LockTimeout time.Duration
+}
+
+// OAuth is where the users service, which validates access tokens, listens
+type OAuth struct {
+	Address string
+}
+
+type Log struct {
+	// Level is the least severe level logged, debug, info, warn or error
+	Level string
+}
+
+type Tracing struct {
+	// Exporter is where spans go, none, stdout, file or otlp
+	Exporter string
+	// File is the file spans are appended to by the file exporter
+	File string
+	// OTLPEndpoint is the host:port of the collector receiving OTLP over HTTP
+	OTLPEndpoint string
+	// SampleRatio is the share of traces started by this service that are
+	// recorded, traces started upstream follow the caller's decision
+	SampleRatio float64
+}
+
+// setting binds a value of the config to the environment variable and flag
+// that set it
+type setting struct {
+	env   string
+	flag  string
+	value string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"PORT", "http-addr", ":8082", "address the REST API listens on", func(c *Config, v string) error {
+		c.HTTP.Addr = v
+		return nil
+	}},
+	{"HTTP_READ_HEADER_TIMEOUT", "http-read-header-timeout", "5s", "time allowed to read a request's headers", func(c *Config, v string) error {
+		return parseDuration(&c.HTTP.ReadHeaderTimeout, v)
+	}},
+	{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "5s", "time allowed to in-flight requests once the service is asked to stop", func(c *Config, v string) error {
+		return parseDuration(&c.HTTP.ShutdownTimeout, v)
+	}},
+	{"SHUTDOWN_DRAIN_DELAY", "shutdown-drain-delay", "0s", "time the service keeps serving with readiness failing once asked to stop", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.HTTP.DrainDelay, v)
+	}},
+	{"READINESS_TIMEOUT", "readiness-timeout", "2s", "time each dependency has to answer a readiness check", func(c *Config, v string) error {
+		return parseDuration(&c.HTTP.ReadinessTimeout, v)
+	}},
+	{"TRUSTED_PROXIES", "trusted-proxies", "", "comma separated IPs and CIDRs of the proxies whose forwarding headers are believed", func(c *Config, v string) error {
+		return parseList(&c.HTTP.TrustedProxies, v)
+	}},
+	{"RATE_LIMIT_READ_ANONYMOUS", "rate-limit-read-anonymous", "120/1m", "requests an IP can make to the public routes, as requests/period or off", func(c *Config, v string) error {
+		return parseRate(&c.HTTP.RateLimits.Read.Anonymous, v)
+	}},
+	{"RATE_LIMIT_READ_USER", "rate-limit-read-user", "600/1m", "requests a user can make to the public routes, as requests/period or off", func(c *Config, v string) error {
+		return parseRate(&c.HTTP.RateLimits.Read.User, v)
+	}},
+	{"RATE_LIMIT_WRITE_ANONYMOUS", "rate-limit-write-anonymous", "30/1m", "requests an IP can make to the authenticated routes without a valid token, as requests/period or off", func(c *Config, v string) error {
+		return parseRate(&c.HTTP.RateLimits.Write.Anonymous, v)
+	}},
+	{"RATE_LIMIT_WRITE_USER", "rate-limit-write-user", "120/1m", "requests a user can make to the authenticated routes, as requests/period or off", func(c *Config, v string) error {
+		return parseRate(&c.HTTP.RateLimits.Write.User, v)
+	}},
+	{"RATE_LIMIT_BULK_ANONYMOUS", "rate-limit-bulk-anonymous", "10/1m", "requests an IP can make to imports and exports without a valid token, as requests/period or off", func(c *Config, v string) error {
+		return parseRate(&c.HTTP.RateLimits.Bulk.Anonymous, v)
+	}},
+	{"RATE_LIMIT_BULK_USER", "rate-limit-bulk-user", "10/1m", "requests a user can make to imports and exports, as requests/period or off", func(c *Config, v string) error {
+		return parseRate(&c.HTTP.RateLimits.Bulk.User, v)
+	}},
+	{"CORS_ALLOWED_ORIGINS", "cors-allowed-origins", "", "comma separated origins browsers may call the API from, * for any", func(c *Config, v string) error {
+		return parseList(&c.HTTP.CORS.AllowedOrigins, v)
+	}},
+	{"CORS_ALLOWED_METHODS", "cors-allowed-methods", "GET,POST,PUT", "comma separated methods cross-origin requests may use", func(c *Config, v string) error {
+		return parseList(&c.HTTP.CORS.AllowedMethods, strings.ToUpper(v))
+	}},
+	{"CORS_ALLOWED_HEADERS", "cors-allowed-headers", "Authorization,Content-Type,If-Match,If-None-Match,X-Request-ID", "comma separated headers cross-origin requests may send", func(c *Config, v string) error {
+		return parseList(&c.HTTP.CORS.AllowedHeaders, v)
+	}},
+	{"CORS_EXPOSED_HEADERS", "cors-exposed-headers", "ETag,Link,Deprecation,Sunset,Retry-After,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,X-Request-ID", "comma separated response headers cross-origin callers may read", func(c *Config, v string) error {
+		return parseList(&c.HTTP.CORS.ExposedHeaders, v)
+	}},
+	{"CORS_ALLOW_CREDENTIALS", "cors-allow-credentials", "false", "whether cross-origin requests may carry cookies and other credentials", func(c *Config, v string) error {
+		return parseBool(&c.HTTP.CORS.AllowCredentials, v)
+	}},
+	{"CORS_MAX_AGE", "cors-max-age", "10m", "time browsers may cache the answer to a preflight request", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.HTTP.CORS.MaxAge, v)
+	}},
+	{"HSTS_MAX_AGE", "hsts-max-age", "0s", "time browsers must only reach the API over HTTPS, 0 to not send Strict-Transport-Security", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.HTTP.HSTSMaxAge, v)
+	}},
+	{"GRPC_PORT", "grpc-addr", ":8083", "address the gRPC API listens on", func(c *Config, v string) error {
+		c.GRPC.Addr = v
+		return nil
+	}},
+	{"MYSQL_USER", "mysql-user", "root", "MySQL user", func(c *Config, v string) error {
+		c.MySQL.User = v
+		return nil
+	}},
+	{"MYSQL_PASSWORD", "mysql-password", "", "MySQL password, prefer setting it through the environment", func(c *Config, v string) error {
+		c.MySQL.Password = v
+		return nil
+	}},
+	{"MYSQL_ADDRESS", "mysql-address", "127.0.0.1:3306", "MySQL host:port", func(c *Config, v string) error {
+		c.MySQL.Address = v
+		return nil
+	}},
+	{"MYSQL_DB", "mysql-db", "books_db", "MySQL database", func(c *Config, v string) error {
+		c.MySQL.Database = v
+		return nil
+	}},
+	{"MYSQL_TLS", "mysql-tls", "false", "TLS mode, false, true, skip-verify or preferred", func(c *Config, v string) error {
+		c.MySQL.TLS = v
+		return nil
+	}},
+	{"MYSQL_DIAL_TIMEOUT", "mysql-dial-timeout", "5s", "time allowed to open a connection", func(c *Config, v string) error {
+		return parseDuration(&c.MySQL.DialTimeout, v)
+	}},
+	{"MYSQL_READ_TIMEOUT", "mysql-read-timeout", "30s", "I/O read timeout", func(c *Config, v string) error {
+		return parseDuration(&c.MySQL.ReadTimeout, v)
+	}},
+	{"MYSQL_WRITE_TIMEOUT", "mysql-write-timeout", "30s", "I/O write timeout", func(c *Config, v string) error {
+		return parseDuration(&c.MySQL.WriteTimeout, v)
+	}},
+	{"MYSQL_MAX_OPEN_CONNS", "mysql-max-open-conns", "25", "connections open at most, 0 for no limit", func(c *Config, v string) error {
+		return parseCount(&c.MySQL.MaxOpenConns, v)
+	}},
+	{"MYSQL_MAX_IDLE_CONNS", "mysql-max-idle-conns", "25", "idle connections kept in the pool", func(c *Config, v string) error {
+		return parseCount(&c.MySQL.MaxIdleConns, v)
+	}},
+	{"MYSQL_CONN_MAX_LIFETIME", "mysql-conn-max-lifetime", "5m", "time a connection is reused for, 0 to reuse it forever", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.MySQL.ConnMaxLifetime, v)
+	}},
+	{"MYSQL_CONNECT_DEADLINE", "mysql-connect-deadline", "1m", "time startup keeps retrying to reach MySQL", func(c *Config, v string) error {
+		return parseDuration(&c.MySQL.ConnectDeadline, v)
+	}},
+	{"MYSQL_READ_QUERY_TIMEOUT", "mysql-read-query-timeout", "5s", "time a lookup or listing may run, 0 for no limit", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.MySQL.QueryTimeouts.Read, v)
+	}},
+	{"MYSQL_WRITE_QUERY_TIMEOUT", "mysql-write-query-timeout", "10s", "time a transaction saving or updating records may run, 0 for no limit", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.MySQL.QueryTimeouts.Write, v)
+	}},
+	{"MYSQL_EXPORT_QUERY_TIMEOUT", "mysql-export-query-timeout", "0s", "time exporting the catalog may run, 0 for no limit", func(c *Config, v string) error {
+		return parseOptionalDuration(&c.MySQL.QueryTimeouts.Export, v)
+	}},
+	{"MIGRATE_ON_START", "migrate-on-start", "false", "apply the pending schema migrations on start", func(c *Config, v string) error {
+		return parseBool(&c.Migrate.OnStart, v)
+	}},
+	{"MIGRATE_LOCK_TIMEOUT", "migrate-lock-timeout", "1m", "time a migration run waits for another one to finish", func(c *Config, v string) error {
+		return parseDuration(&c.Migrate.LockTimeout, v)
+	}},
+	{"GRPC_ADDRESS", "oauth-address", "127.0.0.1:10000", "host:port of the users service validating access tokens", func(c *Config, v string) error {
+		c.OAuth.Address = v
+		return nil
+	}},
+	{"LOG_LEVEL", "log-level", "info", "least severe level logged, debug, info, warn or error", func(c *Config, v string) error {
+		c.Log.Level = strings.ToLower(v)
+		return nil
+	}},
+	{"TRACING_EXPORTER", "tracing-exporter", "none", "where spans are exported, none, stdout, file or otlp", func(c *Config, v string) error {
+		c.Tracing.Exporter = strings.ToLower(v)
+		return nil
+	}},
+	{"TRACING_FILE", "tracing-file", "traces.json", "file spans are appended to by the file exporter", func(c *Config, v string) error {
+		c.Tracing.File = v
+		return nil
+	}},
+	{"TRACING_OTLP_ENDPOINT", "tracing-otlp-endpoint", "localhost:4318", "host:port of the OTLP/HTTP collector", func(c *Config, v string) error {
+		c.Tracing.OTLPEndpoint = v
+		return nil
+	}},
+	{"TRACING_SAMPLE_RATIO", "tracing-sample-ratio", "1", "share of new traces recorded, from 0 to 1", func(c *Config, v string) error {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return errors.New("must be a number from 0 to 1")
+		}
+		c.Tracing.SampleRatio = ratio
+		return nil
+	}},
+}
+
+func parseDuration(d *time.Duration, value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return errors.New("must be a positive duration such as 5s or 1m30s")
+	}
+	*d = parsed
+	return nil
+}
+
+// parseOptionalDuration is parseDuration for settings that zero disables
+func parseOptionalDuration(d *time.Duration, value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return errors.New("must be a duration such as 5s or 1m30s, or 0")
+	}
+	*d = parsed
+	return nil
+}
+
+// parseRate reads requests/period, such as 100/1m, the period defaulting to
+// one when only a unit is given as in 10/s
+func parseRate(r *Rate, value string) error {
+	if value == "off" {
+		*r = Rate{}
+		return nil
+	}
+	invalid := errors.New("must be requests/period such as 100/1m or 10/s, or off")
+
+	slash := strings.IndexByte(value, '/')
+	if slash < 0 {
+		return invalid
+	}
+	requests, err := strconv.Atoi(value[:slash])
+	if err != nil || requests <= 0 {
+		return invalid
+	}
+	period := value[slash+1:]
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	parsed, err := time.ParseDuration(period)
+	if err != nil || parsed <= 0 {
+		return invalid
+	}
+
+	*r = Rate{Requests: requests, Period: parsed}
+	return nil
+}
+
+// parseList splits a comma separated setting, an empty one being an empty list
+func parseList(list *[]string, value string) error {
+	*list = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+	return nil
+}
+
+func parseBool(b *bool, value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return errors.New("must be true or false")
+	}
+	*b = parsed
+	return nil
+}
+
+func parseCount(n *int, value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return errors.New("must be a whole number, zero or greater")
+	}
+	*n = parsed
+	return nil
+}
+
+// Error lists every invalid setting, so they can all be fixed at once
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// defaultFile is loaded when no file is given, and only if it exists
+const defaultFile = ".env"
+
+// Load registers the config flags in fs, parses args with it and returns the
+// resulting config. The dotenv file is the one given with -config or
+// CONFIG_FILE, otherwise .env when there's one in the working directory.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	file := fs.String("config", "", "dotenv file to read settings from, "+defaultFile+" when present by default")
+	flags := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flags[s.flag] = fs.String(s.flag, "", fmt.Sprintf("%s, $%s (default %q)", s.usage, s.env, s.value))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.env] = s.value
+	}
+
+	path, required := *file, true
+	if path == "" {
+		path, required = os.Getenv("CONFIG_FILE"), true
+	}
+	if path == "" {
+		path, required = defaultFile, false
+	}
+	fromFile, err := godotenv.Read(path)
+	if err != nil && (required || !errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	for key, value := range fromFile {
+		values[key] = value
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			values[s.env] = value
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				values[s.env] = *flags[s.flag]
+			}
+		}
+	})
+
+	var c Config
+	var problems []string
+	for _, s := range settings {
+		if err := s.set(&c, strings.TrimSpace(values[s.env])); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", s.env, err))
+		}
+	}
+	problems = append(problems, c.validate()...)
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+
+	return &c, nil
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	address := func(env, value string) {
+		if _, port, err := net.SplitHostPort(value); err != nil || port == "" {
+			problems = append(problems, fmt.Sprintf("%s: %q must be an address such as host:port or :port", env, value))
+		}
+	}
+	required := func(env, value string) {
+		if value == "" {
+			problems = append(problems, env+": is required")
+		}
+	}
+
+	address("PORT", c.HTTP.Addr)
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			problems = append(problems, fmt.Sprintf("TRUSTED_PROXIES: %q must be an IP or a CIDR", proxy))
+		}
+	}
+	for _, origin := range c.HTTP.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.HTTP.CORS.AllowCredentials {
+				problems = append(problems, "CORS_ALLOWED_ORIGINS: * can't be used along with CORS_ALLOW_CREDENTIALS, list the origins")
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			problems = append(problems, fmt.Sprintf("CORS_ALLOWED_ORIGINS: %q must be an origin such as https://shop.example.com", origin))
+		}
+	}
+	address("GRPC_PORT", c.GRPC.Addr)
+	required("MYSQL_USER", c.MySQL.User)
+	address("MYSQL_ADDRESS", c.MySQL.Address)
+	required("MYSQL_DB", c.MySQL.Database)
+	switch c.MySQL.TLS {
+	case "false", "true", "skip-verify", "preferred":
+	default:
+		problems = append(problems, fmt.Sprintf("MYSQL_TLS: %q must be false, true, skip-verify or preferred", c.MySQL.TLS))
+	}
+	if c.MySQL.MaxOpenConns > 0 && c.MySQL.MaxIdleConns > c.MySQL.MaxOpenConns {
+		problems = append(problems, "MYSQL_MAX_IDLE_CONNS: can't be greater than MYSQL_MAX_OPEN_CONNS")
+	}
+	address("GRPC_ADDRESS", c.OAuth.Address)
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL: %q must be debug, info, warn or error", c.Log.Level))
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "file":
+		required("TRACING_FILE", c.Tracing.File)
+	case "otlp":
+		address("TRACING_OTLP_ENDPOINT", c.Tracing.OTLPEndpoint)
+	default:
+		problems = append(problems, fmt.Sprintf("TRACING_EXPORTER: %q must be none, stdout, file or otlp", c.Tracing.Exporter))
+	}
+
+	return problems
+}
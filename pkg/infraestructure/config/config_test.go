@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	return Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := load(t)
+
+		assert.Nil(t, err)
+		assert.EqualValues(t, ":8082", cfg.HTTP.Addr)
+		assert.EqualValues(t, 5*time.Second, cfg.HTTP.ShutdownTimeout)
+		assert.EqualValues(t, "books_db", cfg.MySQL.Database)
+		assert.EqualValues(t, QueryTimeouts{Read: 5 * time.Second, Write: 10 * time.Second}, cfg.MySQL.QueryTimeouts)
+		assert.EqualValues(t, Rate{Requests: 120, Period: time.Minute}, cfg.HTTP.RateLimits.Read.Anonymous)
+		assert.Empty(t, cfg.HTTP.TrustedProxies)
+		assert.EqualValues(t, Migrate{OnStart: false, LockTimeout: time.Minute}, cfg.Migrate)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "books.env")
+		assert.Nil(t, os.WriteFile(file, []byte("PORT=:9000\nMYSQL_DB=from_file\nMYSQL_USER=from_file\n"), 0o600))
+		t.Setenv("MYSQL_DB", "from_env")
+		t.Setenv("MYSQL_USER", "from_env")
+
+		cfg, err := load(t, "-config", file, "-mysql-user", "from_flag")
+
+		assert.Nil(t, err)
+		assert.EqualValues(t, ":9000", cfg.HTTP.Addr)
+		assert.EqualValues(t, "from_env", cfg.MySQL.Database)
+		assert.EqualValues(t, "from_flag", cfg.MySQL.User)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := load(t, "-config", "missing.env")
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv("SHUTDOWN_TIMEOUT", "soon")
+		t.Setenv("MYSQL_DB", "")
+		t.Setenv("MYSQL_TLS", "maybe")
+		t.Setenv("MYSQL_MAX_OPEN_CONNS", "-1")
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, proxy")
+		t.Setenv("CORS_ALLOWED_ORIGINS", "https://shop.example.com,shop.example.com")
+
+		_, err := load(t, "-http-addr", "8082")
+
+		var configErr *Error
+		assert.True(t, errors.As(err, &configErr))
+		assert.EqualValues(t, []string{
+			"SHUTDOWN_TIMEOUT: must be a positive duration such as 5s or 1m30s",
+			"MYSQL_MAX_OPEN_CONNS: must be a whole number, zero or greater",
+			`PORT: "8082" must be an address such as host:port or :port`,
+			`TRUSTED_PROXIES: "proxy" must be an IP or a CIDR`,
+			`CORS_ALLOWED_ORIGINS: "shop.example.com" must be an origin such as https://shop.example.com`,
+			"MYSQL_DB: is required",
+			`MYSQL_TLS: "maybe" must be false, true, skip-verify or preferred`,
+		}, configErr.Problems)
+	})
+}
+
+func TestParseRate(t *testing.T) {
+	valid := map[string]Rate{
+		"120/1m": {Requests: 120, Period: time.Minute},
+		"10/s":   {Requests: 10, Period: time.Second},
+		"5/30s":  {Requests: 5, Period: 30 * time.Second},
+		"off":    {},
+	}
+	for value, want := range valid {
+		var rate Rate
+		assert.Nil(t, parseRate(&rate, value), value)
+		assert.EqualValues(t, want, rate, value)
+	}
+
+	for _, value := range []string{"", "120", "0/1m", "-1/1m", "10/", "10/0s", "ten/s"} {
+		var rate Rate
+		assert.NotNil(t, parseRate(&rate, value), value)
+	}
+}
@@ -0,0 +1,46 @@
+package gql
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler serves the catalog GraphQL schema, it expects to be wrapped by an
+// auth middleware that sets the "user_payload" when a token is sent
+func Handler(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	schema := graphql.MustParseSchema(schema, &resolver{br: br},
+		// a whole page of siblings is resolved at once, the loaders batch
+		// it whatever the parallelism though
+		graphql.MaxParallelism(domain.MaxListLimit),
+	)
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), loadersKey{}, newLoaders(c.Request.Context(), br))
+		if payload, exists := c.Get("user_payload"); exists {
+			ctx = context.WithValue(ctx, userKey{}, payload.(auth.UserPayload))
+		}
+
+		response := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		c.JSON(http.StatusOK, response)
+	}
+}
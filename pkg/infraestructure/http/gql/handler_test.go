@@ -0,0 +1,183 @@
+package gql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo serves a fixed catalog, recording the ids every batch was asked
+// for. The methods a test doesn't use panic.
+type fakeRepo struct {
+	ports.BooksRepositoryInterface
+
+	// batches are recorded by the loaders of concurrent resolvers
+	mu      sync.Mutex
+	batches map[string][][]int64
+	saved   bool
+}
+
+func (r *fakeRepo) record(batch string, ids []int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.batches == nil {
+		r.batches = make(map[string][][]int64)
+	}
+	r.batches[batch] = append(r.batches[batch], ids)
+}
+
+func (r *fakeRepo) ListBooks(context.Context, domain.ListOptions) ([]domain.Book, rest_errors.RestErr) {
+	return []domain.Book{
+		{ID: 1, Title: "Ubik", PublisherID: 7, Status: domain.StatusPublished},
+		{ID: 2, Title: "Valis", PublisherID: 7, Status: domain.StatusPublished},
+		{ID: 3, Title: "Ficciones", PublisherID: 8, Status: domain.StatusPublished},
+	}, nil
+}
+
+func (r *fakeRepo) GetAuthorsByBookIds(_ context.Context, ids []int64) (map[int64][]domain.Author, rest_errors.RestErr) {
+	r.record("authors by book", ids)
+	dick := domain.Author{ID: 1, FirstName: "Philip", LastName: "Dick", Biography: "a weird biography ..."}
+	borges := domain.Author{ID: 2, FirstName: "Jorge Luis", LastName: "Borges", Biography: "another biography"}
+	return map[int64][]domain.Author{1: {dick}, 2: {dick}, 3: {borges}}, nil
+}
+
+func (r *fakeRepo) GetPublishersByIds(_ context.Context, ids []int64) (map[int64]domain.Publisher, rest_errors.RestErr) {
+	r.record("publishers", ids)
+	return map[int64]domain.Publisher{
+		7: {ID: 7, Name: "Doubleday"},
+		8: {ID: 8, Name: "Sur"},
+	}, nil
+}
+
+func (r *fakeRepo) GetAuthorsByPublisherIds(_ context.Context, ids []int64) (map[int64][]domain.Author, rest_errors.RestErr) {
+	r.record("authors by publisher", ids)
+	return nil, nil
+}
+
+func (r *fakeRepo) GetBooksByAuthorIds(_ context.Context, ids []int64) (map[int64][]domain.Book, rest_errors.RestErr) {
+	r.record("books by author", ids)
+	return map[int64][]domain.Book{
+		1: {{ID: 1, Title: "Ubik", PublisherID: 7}, {ID: 2, Title: "Valis", PublisherID: 7}, {ID: 4, Title: "Ubik: The Screenplay", PublisherID: 9}},
+		2: {{ID: 3, Title: "Ficciones", PublisherID: 8}},
+	}, nil
+}
+
+func (r *fakeRepo) GetBooksByPublisherIds(_ context.Context, ids []int64) (map[int64][]domain.Book, rest_errors.RestErr) {
+	r.record("books by publisher", ids)
+	return nil, nil
+}
+
+func (r *fakeRepo) SavePublisher(context.Context, int64, *domain.Publisher) rest_errors.RestErr {
+	r.saved = true
+	return nil
+}
+
+func TestBooksQueryIsBatched(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeRepo{}
+	router := gin.New()
+	router.POST("/graphql", Handler(repo))
+
+	body, _ := json.Marshal(request{
+		Query: `{ books { title publisher { name } authors { lastName biography } } }`,
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	var response struct {
+		Data struct {
+			Books []struct {
+				Title     string
+				Publisher struct{ Name string }
+				Authors   []struct{ LastName, Biography string }
+			}
+		}
+		Errors []interface{}
+	}
+	assert.EqualValues(t, http.StatusOK, rec.Code)
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Empty(t, response.Errors)
+	if assert.Len(t, response.Data.Books, 3) {
+		assert.EqualValues(t, "Sur", response.Data.Books[2].Publisher.Name)
+		assert.EqualValues(t, "another biography", response.Data.Books[2].Authors[0].Biography)
+	}
+	// one call per relationship, no matter how many books are listed
+	assert.EqualValues(t, map[string][][]int64{
+		"authors by book": {{1, 2, 3}},
+		"publishers":      {{7, 8}},
+	}, repo.batches)
+}
+
+func TestNestedLevelsAreBatched(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeRepo{}
+	router := gin.New()
+	router.POST("/graphql", Handler(repo))
+
+	body, _ := json.Marshal(request{
+		Query: `{ books { authors { books { title publisher { name } } } } }`,
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	var response struct {
+		Data struct {
+			Books []struct {
+				Authors []struct {
+					Books []struct {
+						Title     string
+						Publisher struct{ Name string }
+					}
+				}
+			}
+		}
+		Errors []interface{}
+	}
+	assert.EqualValues(t, http.StatusOK, rec.Code)
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Empty(t, response.Errors)
+	if assert.Len(t, response.Data.Books, 3) && assert.Len(t, response.Data.Books[0].Authors, 1) {
+		assert.Len(t, response.Data.Books[0].Authors[0].Books, 3)
+	}
+	// every level takes one call, the publishers of the books of the
+	// authors included
+	assert.EqualValues(t, map[string][][]int64{
+		"authors by book": {{1, 2, 3}},
+		"books by author": {{1, 2}},
+		"publishers":      {{7, 8, 9}},
+	}, repo.batches)
+}
+
+func TestMutationsRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &fakeRepo{}
+	router := gin.New()
+	router.POST("/graphql", Handler(repo))
+
+	body, _ := json.Marshal(request{
+		Query: `mutation { createPublisher(input: {name: "Sur", description: "", slogan: "", founded: "1931-01-01"}) { id } }`,
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	var response struct {
+		Errors []struct{ Message string }
+	}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Len(t, response.Errors, 1)
+	assert.EqualValues(t, errUnauthorized.Error(), response.Errors[0].Message)
+	assert.False(t, repo.saved)
+}
@@ -0,0 +1,79 @@
+package gql
+
+import (
+	"sort"
+	"sync"
+)
+
+type batchFunc func(keys []int64) (map[int64]interface{}, error)
+
+type batch struct {
+	keys    []int64
+	fetched sync.Once
+	done    chan struct{}
+	results map[int64]interface{}
+	err     error
+}
+
+// loader coalesces the keys of sibling resolvers into a single repository
+// call and caches results for the rest of the request.
+//
+// The resolvers returning a level of siblings announce the keys the level is
+// going to load with expect, the first of them to Load fetches them all. A
+// level takes one call whatever the order its resolvers are scheduled in.
+type loader struct {
+	fetch batchFunc
+
+	mu      sync.Mutex
+	pending *batch
+	loaded  map[int64]*batch
+}
+
+func newLoader(fetch batchFunc) *loader {
+	return &loader{
+		fetch:  fetch,
+		loaded: make(map[int64]*batch),
+	}
+}
+
+// expect adds the keys that weren't asked for yet to the batch the next Load
+// fetches
+func (l *loader) expect(keys ...int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expectLocked(keys)
+}
+
+func (l *loader) expectLocked(keys []int64) {
+	for _, key := range keys {
+		if _, ok := l.loaded[key]; ok {
+			continue
+		}
+		if l.pending == nil {
+			l.pending = &batch{done: make(chan struct{})}
+		}
+		l.pending.keys = append(l.pending.keys, key)
+		l.loaded[key] = l.pending
+	}
+}
+
+func (l *loader) Load(key int64) (interface{}, error) {
+	l.mu.Lock()
+	l.expectLocked([]int64{key})
+	b := l.loaded[key]
+	if l.pending == b {
+		// keys expected from now on go to the next batch
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	b.fetched.Do(func() { l.dispatch(b) })
+	<-b.done
+	return b.results[key], b.err
+}
+
+func (l *loader) dispatch(b *batch) {
+	sort.Slice(b.keys, func(i, j int) bool { return b.keys[i] < b.keys[j] })
+	b.results, b.err = l.fetch(b.keys)
+	close(b.done)
+}
@@ -0,0 +1,103 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+type loadersKey struct{}
+
+// loaders are created for every request, so cached results never outlive it
+// and batches are canceled along with it
+type loaders struct {
+	publisher          *loader
+	authorsByBook      *loader
+	authorsByPublisher *loader
+	booksByAuthor      *loader
+	booksByPublisher   *loader
+}
+
+func newLoaders(ctx context.Context, br ports.BooksRepositoryInterface) *loaders {
+	l := &loaders{}
+	l.publisher = newLoader(func(ids []int64) (map[int64]interface{}, error) {
+		publishers, err := br.GetPublishersByIds(ctx, ids)
+		if err != nil {
+			return nil, toError(err)
+		}
+		out := make(map[int64]interface{}, len(publishers))
+		for id, p := range publishers {
+			l.expectPublishers(p)
+			out[id] = p
+		}
+		return out, nil
+	})
+	l.authorsByBook = newLoader(l.authorsBatch(ctx, br.GetAuthorsByBookIds))
+	l.authorsByPublisher = newLoader(l.authorsBatch(ctx, br.GetAuthorsByPublisherIds))
+	l.booksByAuthor = newLoader(l.booksBatch(ctx, br.GetBooksByAuthorIds))
+	l.booksByPublisher = newLoader(l.booksBatch(ctx, br.GetBooksByPublisherIds))
+	return l
+}
+
+// expectBooks announces the relationships of a level of books, see loader.
+// Batches announce the level they fetched before handing it out.
+func (l *loaders) expectBooks(books ...domain.Book) {
+	for _, b := range books {
+		l.publisher.expect(b.PublisherID)
+		l.authorsByBook.expect(b.ID)
+	}
+}
+
+func (l *loaders) expectAuthors(authors ...domain.Author) {
+	for _, a := range authors {
+		l.booksByAuthor.expect(a.ID)
+	}
+}
+
+func (l *loaders) expectPublishers(publishers ...domain.Publisher) {
+	for _, p := range publishers {
+		l.authorsByPublisher.expect(p.ID)
+		l.booksByPublisher.expect(p.ID)
+	}
+}
+
+func (l *loaders) authorsBatch(ctx context.Context, get func(context.Context, []int64) (map[int64][]domain.Author, rest_errors.RestErr)) batchFunc {
+	return func(ids []int64) (map[int64]interface{}, error) {
+		authors, err := get(ctx, ids)
+		if err != nil {
+			return nil, toError(err)
+		}
+		out := make(map[int64]interface{}, len(authors))
+		for id, a := range authors {
+			l.expectAuthors(a...)
+			out[id] = a
+		}
+		return out, nil
+	}
+}
+
+func (l *loaders) booksBatch(ctx context.Context, get func(context.Context, []int64) (map[int64][]domain.Book, rest_errors.RestErr)) batchFunc {
+	return func(ids []int64) (map[int64]interface{}, error) {
+		books, err := get(ctx, ids)
+		if err != nil {
+			return nil, toError(err)
+		}
+		out := make(map[int64]interface{}, len(books))
+		for id, b := range books {
+			l.expectBooks(b...)
+			out[id] = b
+		}
+		return out, nil
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func toError(err rest_errors.RestErr) error {
+	return errors.New(err.Message())
+}
@@ -0,0 +1,273 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type userKey struct{}
+
+var (
+	errUnauthorized = errors.New("you don't have the permissions to access this resource")
+	errNotFound     = errors.New("not found")
+)
+
+type resolver struct {
+	br ports.BooksRepositoryInterface
+}
+
+type listArgs struct {
+	AfterID *graphql.ID
+	Limit   *int32
+}
+
+func (a listArgs) options() (domain.ListOptions, error) {
+	var opts domain.ListOptions
+	if a.AfterID != nil {
+		id, err := parseID(*a.AfterID)
+		if err != nil {
+			return opts, err
+		}
+		opts.AfterID = id
+	}
+	if a.Limit != nil {
+		opts.Limit = int(*a.Limit)
+	}
+	return opts.Normalize(), nil
+}
+
+func parseID(id graphql.ID) (int64, error) {
+	parsed, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid id")
+	}
+	return parsed, nil
+}
+
+func toID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+func userFrom(ctx context.Context) (auth.UserPayload, bool) {
+	user, ok := ctx.Value(userKey{}).(auth.UserPayload)
+	return user, ok
+}
+
+func adminFrom(ctx context.Context) (auth.UserPayload, error) {
+	user, ok := userFrom(ctx)
+	if !ok || user.Role != "admin" {
+		return user, errUnauthorized
+	}
+	return user, nil
+}
+
+// Queries
+
+func (r *resolver) Book(ctx context.Context, args struct{ ID graphql.ID }) (*bookResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	book, restErr := r.br.GetBookById(ctx, id)
+	if restErr != nil {
+		return nil, toError(restErr)
+	}
+
+	if !domain.IsPublicStatus(book.Book.Status) {
+		user, ok := userFrom(ctx)
+		if !ok || (user.Role != "admin" && user.Id != book.Book.SellerID) {
+			return nil, errNotFound
+		}
+	}
+
+	book.Book.PublisherID = book.Publisher.ID
+	return &bookResolver{book: book.Book}, nil
+}
+
+func (r *resolver) Books(ctx context.Context, args listArgs) ([]*bookResolver, error) {
+	opts, err := args.options()
+	if err != nil {
+		return nil, err
+	}
+
+	books, restErr := r.br.ListBooks(ctx, opts)
+	if restErr != nil {
+		return nil, toError(restErr)
+	}
+	loadersFrom(ctx).expectBooks(books...)
+	return toBookResolvers(books), nil
+}
+
+func (r *resolver) Author(ctx context.Context, args struct{ ID graphql.ID }) (*authorResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, restErr := r.br.GetAuthorById(ctx, id)
+	if restErr != nil {
+		return nil, toError(restErr)
+	}
+	return &authorResolver{author: author.Author}, nil
+}
+
+func (r *resolver) Authors(ctx context.Context, args listArgs) ([]*authorResolver, error) {
+	opts, err := args.options()
+	if err != nil {
+		return nil, err
+	}
+
+	authors, restErr := r.br.ListAuthors(ctx, opts)
+	if restErr != nil {
+		return nil, toError(restErr)
+	}
+	loadersFrom(ctx).expectAuthors(authors...)
+	return toAuthorResolvers(authors), nil
+}
+
+func (r *resolver) Publisher(ctx context.Context, args struct{ ID graphql.ID }) (*publisherResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher, restErr := r.br.GetPublisherById(ctx, id)
+	if restErr != nil {
+		return nil, toError(restErr)
+	}
+	return &publisherResolver{publisher: publisher.Publisher}, nil
+}
+
+func (r *resolver) Publishers(ctx context.Context, args listArgs) ([]*publisherResolver, error) {
+	opts, err := args.options()
+	if err != nil {
+		return nil, err
+	}
+
+	publishers, restErr := r.br.ListPublishers(ctx, opts)
+	if restErr != nil {
+		return nil, toError(restErr)
+	}
+	loadersFrom(ctx).expectPublishers(publishers...)
+
+	out := make([]*publisherResolver, len(publishers))
+	for i := range publishers {
+		out[i] = &publisherResolver{publisher: publishers[i]}
+	}
+	return out, nil
+}
+
+// Mutations, they follow the same rules as their REST counterparts
+
+type authorInput struct {
+	FirstName string
+	LastName  string
+	Biography string
+	Birthday  string
+	Death     *string
+}
+
+func (r *resolver) CreateAuthor(ctx context.Context, args struct{ Input authorInput }) (*authorResolver, error) {
+	user, err := adminFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	author := domain.Author{
+		FirstName: args.Input.FirstName,
+		LastName:  args.Input.LastName,
+		Biography: args.Input.Biography,
+		Birthday:  args.Input.Birthday,
+		Death:     args.Input.Death,
+	}
+	if restErr := r.br.SaveAuthor(ctx, user.Id, &author); restErr != nil {
+		return nil, toError(restErr)
+	}
+	return &authorResolver{author: author}, nil
+}
+
+type publisherInput struct {
+	Name        string
+	Description string
+	Slogan      string
+	Founded     string
+}
+
+func (r *resolver) CreatePublisher(ctx context.Context, args struct{ Input publisherInput }) (*publisherResolver, error) {
+	user, err := adminFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := domain.Publisher{
+		Name:        args.Input.Name,
+		Description: args.Input.Description,
+		Slogan:      args.Input.Slogan,
+		Founded:     args.Input.Founded,
+	}
+	if restErr := r.br.SavePublisher(ctx, user.Id, &publisher); restErr != nil {
+		return nil, toError(restErr)
+	}
+	return &publisherResolver{publisher: publisher}, nil
+}
+
+type bookInput struct {
+	Title            string
+	OriginalRelease  string
+	Description      string
+	ShortDescription string
+	Published        string
+	PublisherID      graphql.ID
+	Pages            int32
+	AuthorIDs        []graphql.ID
+	Price            *int32
+}
+
+func (r *resolver) CreateBook(ctx context.Context, args struct{ Input bookInput }) (*bookResolver, error) {
+	user, err := adminFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	publisherID, err := parseID(args.Input.PublisherID)
+	if err != nil {
+		return nil, err
+	}
+
+	book := domain.Book{
+		Title:            args.Input.Title,
+		OriginalRelease:  args.Input.OriginalRelease,
+		Description:      args.Input.Description,
+		ShortDescription: args.Input.ShortDescription,
+		Published:        args.Input.Published,
+		PublisherID:      publisherID,
+		Pages:            int64(args.Input.Pages),
+		SellerID:         user.Id,
+		Status:           domain.StatusPendingReview,
+	}
+	for _, authorID := range args.Input.AuthorIDs {
+		id, err := parseID(authorID)
+		if err != nil {
+			return nil, err
+		}
+		book.AuthorID = append(book.AuthorID, id)
+	}
+	if args.Input.Price != nil {
+		if *args.Input.Price < 0 {
+			return nil, errors.New("price can't be negative")
+		}
+		book.Price = int64(*args.Input.Price)
+	}
+
+	if restErr := r.br.SaveBook(ctx, user.Id, &book); restErr != nil {
+		return nil, toError(restErr)
+	}
+	return &bookResolver{book: book}, nil
+}
@@ -0,0 +1,85 @@
+package gql
+
+const schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	book(id: ID!): Book
+	books(afterId: ID, limit: Int): [Book!]!
+	author(id: ID!): Author
+	authors(afterId: ID, limit: Int): [Author!]!
+	publisher(id: ID!): Publisher
+	publishers(afterId: ID, limit: Int): [Publisher!]!
+}
+
+type Mutation {
+	createAuthor(input: AuthorInput!): Author!
+	createPublisher(input: PublisherInput!): Publisher!
+	createBook(input: BookInput!): Book!
+}
+
+type Book {
+	id: ID!
+	title: String!
+	originalRelease: String!
+	description: String!
+	shortDescription: String!
+	published: String!
+	pages: Int!
+	sellerId: ID!
+	price: Int!
+	status: String!
+	publisher: Publisher
+	authors: [Author!]!
+}
+
+type Author {
+	id: ID!
+	firstName: String!
+	lastName: String!
+	biography: String!
+	birthday: String!
+	death: String
+	books: [Book!]!
+}
+
+type Publisher {
+	id: ID!
+	name: String!
+	description: String!
+	slogan: String!
+	founded: String!
+	authors: [Author!]!
+	books: [Book!]!
+}
+
+input AuthorInput {
+	firstName: String!
+	lastName: String!
+	biography: String!
+	birthday: String!
+	death: String
+}
+
+input PublisherInput {
+	name: String!
+	description: String!
+	slogan: String!
+	founded: String!
+}
+
+input BookInput {
+	title: String!
+	originalRelease: String!
+	description: String!
+	shortDescription: String!
+	published: String!
+	publisherId: ID!
+	pages: Int!
+	authorIds: [ID!]!
+	price: Int
+}
+`
@@ -0,0 +1,100 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type bookResolver struct {
+	book domain.Book
+}
+
+func toBookResolvers(books []domain.Book) []*bookResolver {
+	out := make([]*bookResolver, len(books))
+	for i := range books {
+		out[i] = &bookResolver{book: books[i]}
+	}
+	return out
+}
+
+func (b *bookResolver) ID() graphql.ID           { return toID(b.book.ID) }
+func (b *bookResolver) Title() string            { return b.book.Title }
+func (b *bookResolver) OriginalRelease() string  { return b.book.OriginalRelease }
+func (b *bookResolver) Description() string      { return b.book.Description }
+func (b *bookResolver) ShortDescription() string { return b.book.ShortDescription }
+func (b *bookResolver) Published() string        { return b.book.Published }
+func (b *bookResolver) Pages() int32             { return int32(b.book.Pages) }
+func (b *bookResolver) SellerID() graphql.ID     { return toID(b.book.SellerID) }
+func (b *bookResolver) Price() int32             { return int32(b.book.Price) }
+func (b *bookResolver) Status() string           { return b.book.Status }
+
+func (b *bookResolver) Publisher(ctx context.Context) (*publisherResolver, error) {
+	publisher, err := loadersFrom(ctx).publisher.Load(b.book.PublisherID)
+	if err != nil || publisher == nil {
+		return nil, err
+	}
+	return &publisherResolver{publisher: publisher.(domain.Publisher)}, nil
+}
+
+func (b *bookResolver) Authors(ctx context.Context) ([]*authorResolver, error) {
+	authors, err := loadersFrom(ctx).authorsByBook.Load(b.book.ID)
+	if err != nil || authors == nil {
+		return []*authorResolver{}, err
+	}
+	return toAuthorResolvers(authors.([]domain.Author)), nil
+}
+
+type authorResolver struct {
+	author domain.Author
+}
+
+func toAuthorResolvers(authors []domain.Author) []*authorResolver {
+	out := make([]*authorResolver, len(authors))
+	for i := range authors {
+		out[i] = &authorResolver{author: authors[i]}
+	}
+	return out
+}
+
+func (a *authorResolver) ID() graphql.ID    { return toID(a.author.ID) }
+func (a *authorResolver) FirstName() string { return a.author.FirstName }
+func (a *authorResolver) LastName() string  { return a.author.LastName }
+func (a *authorResolver) Biography() string { return a.author.Biography }
+func (a *authorResolver) Birthday() string  { return a.author.Birthday }
+func (a *authorResolver) Death() *string    { return a.author.Death }
+
+func (a *authorResolver) Books(ctx context.Context) ([]*bookResolver, error) {
+	books, err := loadersFrom(ctx).booksByAuthor.Load(a.author.ID)
+	if err != nil || books == nil {
+		return []*bookResolver{}, err
+	}
+	return toBookResolvers(books.([]domain.Book)), nil
+}
+
+type publisherResolver struct {
+	publisher domain.Publisher
+}
+
+func (p *publisherResolver) ID() graphql.ID      { return toID(p.publisher.ID) }
+func (p *publisherResolver) Name() string        { return p.publisher.Name }
+func (p *publisherResolver) Description() string { return p.publisher.Description }
+func (p *publisherResolver) Slogan() string      { return p.publisher.Slogan }
+func (p *publisherResolver) Founded() string     { return p.publisher.Founded }
+
+func (p *publisherResolver) Authors(ctx context.Context) ([]*authorResolver, error) {
+	authors, err := loadersFrom(ctx).authorsByPublisher.Load(p.publisher.ID)
+	if err != nil || authors == nil {
+		return []*authorResolver{}, err
+	}
+	return toAuthorResolvers(authors.([]domain.Author)), nil
+}
+
+func (p *publisherResolver) Books(ctx context.Context) ([]*bookResolver, error) {
+	books, err := loadersFrom(ctx).booksByPublisher.Load(p.publisher.ID)
+	if err != nil || books == nil {
+		return []*bookResolver{}, err
+	}
+	return toBookResolvers(books.([]domain.Book)), nil
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+func getAuditLog(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		filter, restErr := parseAuditFilter(c)
+		if restErr != nil {
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		entries, err := br.GetAuditLog(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	}
+}
+
+func parseAuditFilter(c *gin.Context) (domain.AuditFilter, rest_errors.RestErr) {
+	var filter domain.AuditFilter
+
+	if entity := c.Query("entity"); entity != "" {
+		if !domain.IsAuditedEntity(entity) {
+			return filter, rest_errors.NewBadRequestError("entity must be one of book, author or publisher")
+		}
+		filter.Entity = entity
+	}
+
+	if actor := c.Query("actor_id"); actor != "" {
+		actorID, err := strconv.ParseInt(actor, 10, 64)
+		if err != nil {
+			return filter, rest_errors.NewBadRequestError("invalid actor id")
+		}
+		filter.ActorID = actorID
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from")); err != nil {
+		return filter, rest_errors.NewBadRequestError("invalid from timestamp")
+	}
+	if filter.To, err = parseAuditTime(c.Query("to")); err != nil {
+		return filter, rest_errors.NewBadRequestError("invalid to timestamp")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, rest_errors.NewBadRequestError("invalid limit")
+		}
+	}
+
+	return filter, nil
+}
+
+// parseAuditTime accepts either a date or a full timestamp and normalizes it
+// into the layout audit entries are stored with
+func parseAuditTime(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	for _, layout := range []string{domain.TimestampLayout, "2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(domain.TimestampLayout), nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized timestamp %q", value)
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+type bulkResult struct {
+	Index  int                 `json:"index"`
+	Status int                 `json:"status"`
+	Book   *domain.Book        `json:"book,omitempty"`
+	Error  rest_errors.RestErr `json:"error,omitempty"`
+}
+
+type bulkResponse struct {
+	Mode    string       `json:"mode"`
+	Created int          `json:"created"`
+	Failed  int          `json:"failed"`
+	Results []bulkResult `json:"results"`
+}
+
+func createBooks(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode := c.DefaultQuery("mode", domain.BulkAtomic)
+		if !domain.IsValidBulkMode(mode) {
+			restErr := rest_errors.NewBadRequestError("mode must be either atomic or best_effort")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var books []domain.Book
+		if err := c.ShouldBindJSON(&books); err != nil || len(books) == 0 {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+		if len(books) > domain.MaxBulkBooks {
+			restErr := rest_errors.NewBadRequestError(fmt.Sprintf("at most %d books can be created at once", domain.MaxBulkBooks))
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		response := bulkResponse{Mode: mode, Results: make([]bulkResult, len(books))}
+
+		// valid holds the books that made it through validation, and indexes
+		// their position in the request
+		var valid []domain.Book
+		var indexes []int
+		var causes []interface{}
+		for i := range books {
+			books[i].SellerID = authorizedUser.Id
+			books[i].Status = domain.StatusPendingReview
+
+			response.Results[i].Index = i
+			if err := validateNewBook(&books[i]); err != nil {
+				response.Results[i].Status = err.Status()
+				response.Results[i].Error = err
+				causes = append(causes, fmt.Sprintf("book %d: %s", i, err.Message()))
+				continue
+			}
+			valid = append(valid, books[i])
+			indexes = append(indexes, i)
+		}
+
+		if mode == domain.BulkAtomic {
+			if len(causes) > 0 {
+				restErr := rest_errors.NewRestError("some of the books are invalid, none was created", http.StatusBadRequest, "bad_request", causes)
+				c.JSON(restErr.Status(), restErr)
+				return
+			}
+			if err := br.SaveBooks(c.Request.Context(), authorizedUser.Id, valid); err != nil {
+				c.JSON(err.Status(), err)
+				return
+			}
+		} else if err := br.SaveBooks(c.Request.Context(), authorizedUser.Id, valid); err != nil {
+			// a single bad book fails the whole batch, so it's retried one by
+			// one to find out which
+			for j := range valid {
+				if err := br.SaveBook(c.Request.Context(), authorizedUser.Id, &valid[j]); err != nil {
+					response.Results[indexes[j]].Status = err.Status()
+					response.Results[indexes[j]].Error = err
+					indexes[j] = -1
+				}
+			}
+		}
+
+		for j, i := range indexes {
+			if i < 0 {
+				continue
+			}
+			response.Results[i].Status = http.StatusCreated
+			response.Results[i].Book = &valid[j]
+		}
+		for _, result := range response.Results {
+			if result.Error == nil {
+				response.Created++
+			} else {
+				response.Failed++
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
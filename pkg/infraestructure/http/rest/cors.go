@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/gin-gonic/gin"
+)
+
+// cors answers the preflight requests of the origins cfg allows and lets
+// them read the responses to their requests. Requests from other origins are
+// served as usual, without CORS headers, so browsers keep their responses
+// from the page that sent them.
+func cors(cfg config.CORS) gin.HandlerFunc {
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || len(origins) == 0 {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !anyOrigin && !origins[strings.ToLower(origin)] {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// credentials are never sent along with a wildcard
+		if anyOrigin && !cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposed != "" {
+			h.Set("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	}
+}
+
+// apiCSP forbids loading anything from the API's responses, which are data
+// rather than pages. The docs page sets its own.
+const apiCSP = "default-src 'none'; frame-ancestors 'none'"
+
+// securityHeaders keeps browsers from sniffing, framing or leaking the
+// address of the API's responses, and from reaching it over plain HTTP once
+// hstsMaxAge is set
+func securityHeaders(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int(hstsMaxAge.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Content-Security-Policy", apiCSP)
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/metrics"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRouter(cfg config.CORS) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(cors(cfg))
+	router.GET("/v1/books", func(c *gin.Context) {
+		c.Header("ETag", `"1"`)
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestCORS(t *testing.T) {
+	router := corsRouter(config.CORS{
+		AllowedOrigins:   []string{"https://shop.example.com"},
+		AllowedMethods:   []string{"GET", "POST", "PUT"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	request := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/books", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	preflight := map[string]string{
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "authorization, content-type",
+	}
+
+	t.Run("Preflight", func(t *testing.T) {
+		rec := request(http.MethodOptions, "https://shop.example.com", preflight)
+
+		assert.EqualValues(t, http.StatusNoContent, rec.Code)
+		assert.EqualValues(t, "https://shop.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.EqualValues(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.EqualValues(t, "GET, POST, PUT", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.EqualValues(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.EqualValues(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+		assert.EqualValues(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, rec.Header().Values("Vary"))
+	})
+
+	t.Run("PreflightFromOtherOrigin", func(t *testing.T) {
+		rec := request(http.MethodOptions, "https://evil.example.com", preflight)
+
+		assert.EqualValues(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		rec := request(http.MethodGet, "https://shop.example.com", nil)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, "https://shop.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.EqualValues(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.EqualValues(t, "ETag", rec.Header().Get("Access-Control-Expose-Headers"))
+		assert.EqualValues(t, "Origin", rec.Header().Get("Vary"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("SimpleFromOtherOrigin", func(t *testing.T) {
+		rec := request(http.MethodGet, "https://evil.example.com", nil)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.EqualValues(t, "Origin", rec.Header().Get("Vary"))
+	})
+
+	t.Run("SameOrigin", func(t *testing.T) {
+		rec := request(http.MethodGet, "", nil)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Vary"))
+	})
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	router := corsRouter(config.CORS{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/books", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.EqualValues(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	router := testRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.EqualValues(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.EqualValues(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.EqualValues(t, "no-referrer", rec.Header().Get("Referrer-Policy"))
+	assert.EqualValues(t, apiCSP, rec.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.EqualValues(t, docsCSP, rec.Header().Get("Content-Security-Policy"))
+
+	hsts := gin.New()
+	hsts.Use(securityHeaders(365 * 24 * time.Hour))
+	hsts.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	rec = httptest.NewRecorder()
+	hsts.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.EqualValues(t, "max-age=31536000; includeSubDomains", rec.Header().Get("Strict-Transport-Security"))
+}
+
+func TestCORSPreflightReachesNoRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &Server{
+		oauthC:   &auth.Client{},
+		metrics:  metrics.New(),
+		logger:   zerolog.Nop(),
+		limiters: newLimiters(config.RateLimits{}),
+		cors:     config.CORS{AllowedOrigins: []string{"https://shop.example.com"}, AllowedMethods: []string{"PUT"}},
+	}
+	router := s.handler(nil)
+
+	// no route answers OPTIONS, preflights are answered before routing matters
+	req := httptest.NewRequest(http.MethodOptions, "/v1/books/7", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.EqualValues(t, http.StatusNoContent, rec.Code)
+	assert.EqualValues(t, "PUT", rec.Header().Get("Access-Control-Allow-Methods"))
+}
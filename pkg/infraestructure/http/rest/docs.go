@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	//go:embed openapi.json
+	openAPISpec []byte
+
+	//go:embed swagger.html
+	swaggerUI []byte
+
+	// Swagger UI 4.15.5, as swagger-ui-dist publishes it (make swaggerui), and
+	// the script starting it. It's served from here so the docs page doesn't
+	// depend on a CDN nor trust one to run scripts on our origin.
+	//go:embed swagger-ui/swagger-ui-bundle.js swagger-ui/swagger-ui.css swagger-ui/swagger-initializer.js
+	swaggerUIFiles embed.FS
+
+	swaggerUIAssets, _ = fs.Sub(swaggerUIFiles, "swagger-ui")
+)
+
+func getOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
+
+// docsCSP only lets the docs page load the assets served along with it, and
+// fetch the spec
+const docsCSP = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' data:; " +
+	"connect-src 'self'; frame-ancestors 'none'"
+
+func getDocs(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUI)
+}
+
+func getDocsAsset(c *gin.Context) {
+	asset := c.Param("asset")
+	if _, err := fs.Stat(swaggerUIAssets, asset); err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.FileFromFS(asset, http.FS(swaggerUIAssets))
+}
@@ -0,0 +1,128 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/metrics"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPIDocument struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func testRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{oauthC: &auth.Client{}, metrics: metrics.New(), logger: zerolog.Nop(), limiters: newLimiters(config.RateLimits{})}
+	return s.handler(&fakeRepo{})
+}
+
+var ginParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// openAPIPath turns gin's /books/:book_id into OpenAPI's /books/{book_id}
+func openAPIPath(ginPath string) string {
+	return ginParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	var doc openAPIDocument
+	assert.Nil(t, json.Unmarshal(openAPISpec, &doc))
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."))
+
+	for _, route := range testRouter(t).Routes() {
+		path := openAPIPath(route.Path)
+		if _, documented := doc.Paths[path]; !documented && !strings.HasPrefix(path, "/v1/") {
+			// deprecated aliases are covered by their /v1 successor
+			path = "/v1" + path
+		}
+
+		operations, ok := doc.Paths[path]
+		if !assert.True(t, ok, "%s is missing from openapi.json", path) {
+			continue
+		}
+		_, ok = operations[strings.ToLower(route.Method)]
+		assert.True(t, ok, "%s %s is missing from openapi.json", route.Method, path)
+	}
+}
+
+func TestOpenAPIHasNoStaleRoutes(t *testing.T) {
+	var doc openAPIDocument
+	assert.Nil(t, json.Unmarshal(openAPISpec, &doc))
+
+	registered := map[string]bool{}
+	for _, route := range testRouter(t).Routes() {
+		registered[strings.ToLower(route.Method)+" "+openAPIPath(route.Path)] = true
+	}
+
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, registered[method+" "+path], "%s %s is documented but not registered", method, path)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	router := testRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.EqualValues(t, http.StatusOK, rec.Code)
+	assert.True(t, json.Valid(rec.Body.Bytes()))
+
+	// the page starts Swagger UI on the spec from a script of its own
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/swagger-initializer.js", nil))
+	assert.EqualValues(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/openapi.json")
+}
+
+func TestServeDocs(t *testing.T) {
+	router := testRouter(t)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Page", func(t *testing.T) {
+		rec := get("/docs")
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "https://")
+
+		csp := rec.Header().Get("Content-Security-Policy")
+		assert.Contains(t, csp, "script-src 'self';")
+		assert.NotContains(t, csp, "unsafe-inline")
+		assert.NotContains(t, csp, "unpkg.com")
+	})
+
+	t.Run("Assets", func(t *testing.T) {
+		for asset, contentType := range map[string]string{
+			"swagger-ui-bundle.js":   "javascript",
+			"swagger-ui.css":         "text/css",
+			"swagger-initializer.js": "javascript",
+		} {
+			rec := get("/docs/" + asset)
+			assert.EqualValues(t, http.StatusOK, rec.Code, asset)
+			assert.Contains(t, rec.Header().Get("Content-Type"), contentType, asset)
+			assert.NotEmpty(t, rec.Body.Bytes(), asset)
+		}
+	})
+
+	t.Run("UnknownAsset", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusNotFound, get("/docs/LICENSE").Code)
+	})
+}
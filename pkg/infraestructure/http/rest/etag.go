@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// etag identifies a representation of a record, it's made of the record's
+// version, which If-Match is checked against, and a hash of the body, since
+// denormalized payloads also change when their related records do
+func etag(version int64, body []byte) string {
+	sum := sha1.Sum(body)
+	return fmt.Sprintf(`"%d-%s"`, version, hex.EncodeToString(sum[:8]))
+}
+
+// writeWithETag responds with payload tagged with its ETag, or with 304 when
+// the client already holds that same representation
+func writeWithETag(c *gin.Context, version int64, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		restErr := rest_errors.NewInternalServerError(err.Error())
+		c.JSON(restErr.Status(), restErr)
+		return
+	}
+
+	writeBodyWithETag(c, version, "application/json; charset=utf-8", body)
+}
+
+// writeBodyWithETag is writeWithETag for an already rendered body, tagging it
+// as is, so each representation of a record gets its own ETag
+func writeBodyWithETag(c *gin.Context, version int64, contentType string, body []byte) {
+	tag := etag(version, body)
+	c.Header("ETag", tag)
+
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		if candidate = strings.TrimSpace(candidate); candidate == tag || candidate == "*" {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// expectedVersion returns the version the client's If-Match header refers to,
+// updates without one are refused so nobody overwrites a record blindly
+func expectedVersion(c *gin.Context) (int64, rest_errors.RestErr) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, rest_errors.NewRestError(
+			"updates require an If-Match header with the record's ETag",
+			http.StatusPreconditionRequired,
+			"precondition_required",
+			nil,
+		)
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	if i := strings.Index(tag, "-"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return 0, preconditionFailed()
+	}
+	return version, nil
+}
+
+func preconditionFailed() rest_errors.RestErr {
+	return rest_errors.NewRestError(
+		"If-Match doesn't match the current version of the record",
+		http.StatusPreconditionFailed,
+		"precondition_failed",
+		nil,
+	)
+}
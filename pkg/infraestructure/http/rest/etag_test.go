@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteWithETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	author := domain.Author{ID: 4, FirstName: "Philip", Version: 3}
+	router := gin.New()
+	router.GET("/author", func(c *gin.Context) { writeWithETag(c, author.Version, author) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/author", nil))
+
+	tag := rec.Header().Get("ETag")
+	assert.EqualValues(t, http.StatusOK, rec.Code)
+	assert.Regexp(t, `^"3-[0-9a-f]{16}"$`, tag)
+
+	t.Run("NotModified", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/author", nil)
+		req.Header.Set("If-None-Match", tag)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.EqualValues(t, http.StatusNotModified, rec.Code)
+		assert.EqualValues(t, tag, rec.Header().Get("ETag"))
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/author", nil)
+		req.Header.Set("If-None-Match", `"2-0123456789abcdef"`)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Body.String())
+	})
+}
+
+func TestExpectedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name    string
+		ifMatch string
+		version int64
+		status  int
+	}{
+		{"Missing", "", 0, http.StatusPreconditionRequired},
+		{"Malformed", `"abc"`, 0, http.StatusPreconditionFailed},
+		{"ETag", `"7-0123456789abcdef"`, 7, 0},
+		{"Weak", `W/"7-0123456789abcdef"`, 7, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			if tc.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			version, err := expectedVersion(c)
+			if tc.status != 0 {
+				assert.NotNil(t, err)
+				assert.EqualValues(t, tc.status, err.Status())
+				return
+			}
+			assert.Nil(t, err)
+			assert.EqualValues(t, tc.version, version)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/catalog"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/logging"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// exportBooks streams the whole catalog, once the first book is written the
+// status can't change anymore, so later failures cut the download short
+func exportBooks(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		format := c.DefaultQuery("format", catalog.FormatCSV)
+		if !catalog.IsExportFormat(format) {
+			restErr := rest_errors.NewBadRequestError("format must be one of " + strings.Join(catalog.ExportFormats, ", "))
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		filename := fmt.Sprintf("books-%s.%s", time.Now().UTC().Format("20060102T150405Z"), catalog.Extension(format))
+		c.Header("Content-Type", catalog.ContentType(format))
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		written, err := catalog.Export(c.Request.Context(), br, c.Writer, format)
+		if err == nil {
+			return
+		}
+		if written {
+			logging.FromContext(c.Request.Context()).Warn().Err(err).Msg("export interrupted")
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		restErr := rest_errors.NewInternalServerError(err.Error())
+		c.JSON(restErr.Status(), restErr)
+	}
+}
@@ -1,30 +1,86 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
 	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/biblio"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/logging"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/ratelimit"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/tracing"
 	"github.com/FacuBar/bookstore_utils-go/auth"
 	"github.com/FacuBar/bookstore_utils-go/rest_errors"
 	"github.com/gin-gonic/gin"
 )
 
 func (s *Server) handler(br ports.BooksRepositoryInterface) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	if err := router.SetTrustedProxies(s.trustedProxies); err != nil {
+		s.logger.Error().Err(err).Msg("trusting no proxy")
+		router.SetTrustedProxies(nil)
+	}
+	router.Use(
+		tracing.Middleware(),
+		logging.Middleware(s.logger),
+		logging.Recovery(),
+		s.metrics.Middleware(),
+		securityHeaders(s.hstsMaxAge),
+		cors(s.cors),
+	)
+
+	router.GET("/metrics", gin.WrapH(s.metrics.Handler()))
+	router.GET("/openapi.json", getOpenAPI)
+	router.GET("/docs", getDocs)
+	router.GET("/docs/:asset", getDocsAsset)
+	router.GET("/healthz", getHealth)
+	router.GET("/readyz", getReadiness(s.checks, s.readinessTimeout, s.isDraining))
 
-	router.GET("/authors/:author_id", getAuthor(br))
-	router.GET("/books/:book_id", getBook(br))
-	router.GET("/publishers/:publisher_id", getPublisher(br))
+	s.routesV1(router.Group("/v1"), br)
 
-	router.POST("/authors", auth.RequiresAuth(createAuthor(br), s.oauthC.C))
-	router.POST("/publishers", auth.RequiresAuth(createPublisher(br), s.oauthC.C))
-	router.POST("/books", auth.RequiresAuth(createBook(br), s.oauthC.C))
+	// unversioned routes predate /v1 and are kept until they're sunset
+	s.routesV1(router.Group("/", deprecated("/v1", legacySunset)), br)
 
 	return router
 }
 
+// optionalAuth only validates the caller's token when one is sent, so public
+// resources can still be served to anonymous users
+func (s *Server) optionalAuth(handler gin.HandlerFunc) gin.HandlerFunc {
+	authenticated := s.requiresAuth(handler)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			handler(c)
+			return
+		}
+		authenticated(c)
+	}
+}
+
+// canSeeBook reports whether the caller can read a book, books not yet (or no
+// longer) public are only visible to their seller and to admins
+func canSeeBook(c *gin.Context, book domain.Book) bool {
+	if domain.IsPublicStatus(book.Status) {
+		return true
+	}
+
+	payload, exists := c.Get("user_payload")
+	if !exists {
+		return false
+	}
+	return canManageBook(payload.(auth.UserPayload), book)
+}
+
+// canManageBook reports whether user can change a book, which only its seller
+// and admins can
+func canManageBook(user auth.UserPayload, book domain.Book) bool {
+	return user.Role == "admin" || user.Id == book.SellerID
+}
+
 func createAuthor(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var author domain.Author
@@ -41,7 +97,7 @@ func createAuthor(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 			return
 		}
 
-		if err := br.SaveAuthor(&author); err != nil {
+		if err := br.SaveAuthor(c.Request.Context(), authorizedUser.Id, &author); err != nil {
 			c.JSON(err.Status(), err)
 			return
 		}
@@ -65,7 +121,7 @@ func createPublisher(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 			return
 		}
 
-		if err := br.SavePublisher(&publisher); err != nil {
+		if err := br.SavePublisher(c.Request.Context(), authorizedUser.Id, &publisher); err != nil {
 			c.JSON(err.Status(), err)
 			return
 		}
@@ -90,8 +146,14 @@ func createBook(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 		}
 
 		book.SellerID = authorizedUser.Id
+		book.Status = domain.StatusPendingReview
+
+		if err := validateNewBook(&book); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
 
-		if err := br.SaveBook(&book); err != nil {
+		if err := br.SaveBook(c.Request.Context(), authorizedUser.Id, &book); err != nil {
 			c.JSON(err.Status(), err)
 			return
 		}
@@ -99,6 +161,59 @@ func createBook(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 	}
 }
 
+func validateNewBook(book *domain.Book) rest_errors.RestErr {
+	if book.Price < 0 {
+		return rest_errors.NewBadRequestError("price can't be negative")
+	}
+	if book.ISBN != "" {
+		isbn, err := domain.NormalizeISBN(book.ISBN)
+		if err != nil {
+			return rest_errors.NewBadRequestError(err.Error())
+		}
+		book.ISBN = isbn
+	}
+	return nil
+}
+
+// validateBookUpdate checks a PUT body, which replaces every descriptive field
+// of the book, so the ones its columns can't do without are required
+func validateBookUpdate(book *domain.Book) rest_errors.RestErr {
+	var causes []interface{}
+	if strings.TrimSpace(book.Title) == "" {
+		causes = append(causes, "title is required")
+	}
+	if !isDate(book.OriginalRelease) {
+		causes = append(causes, "original_release must be a YYYY-MM-DD date")
+	}
+	if !isDate(book.Published) {
+		causes = append(causes, "published must be a YYYY-MM-DD date")
+	}
+	if book.PublisherID <= 0 {
+		causes = append(causes, "publisher_id is required")
+	}
+	if book.Pages < 0 {
+		causes = append(causes, "pages can't be negative")
+	}
+
+	seen := make(map[int64]bool, len(book.AuthorID))
+	for _, authorID := range book.AuthorID {
+		if seen[authorID] {
+			causes = append(causes, fmt.Sprintf("author %d is listed more than once", authorID))
+		}
+		seen[authorID] = true
+	}
+
+	if len(causes) > 0 {
+		return rest_errors.NewRestError("invalid book", http.StatusBadRequest, "bad_request", causes)
+	}
+	return nil
+}
+
+func isDate(value string) bool {
+	_, err := time.Parse(domain.DateLayout, value)
+	return err == nil
+}
+
 func getAuthor(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorID, idErr := strconv.ParseInt(c.Param("author_id"), 10, 64)
@@ -108,13 +223,13 @@ func getAuthor(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 			return
 		}
 
-		author, err := br.GetAuthorById(authorID)
+		author, err := br.GetAuthorById(c.Request.Context(), authorID)
 		if err != nil {
 			c.JSON(err.Status(), err)
 			return
 		}
 
-		c.JSON(http.StatusOK, author)
+		writeWithETag(c, author.Author.Version, author)
 	}
 }
 
@@ -127,16 +242,56 @@ func getBook(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 			return
 		}
 
-		book, err := br.GetBookById(bookID)
+		book, err := br.GetBookById(c.Request.Context(), bookID)
 		if err != nil {
 			c.JSON(err.Status(), err)
 			return
 		}
 
-		c.JSON(http.StatusOK, book)
+		if !canSeeBook(c, book.Book) {
+			restErr := rest_errors.NewNotFoundError("book not found")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		format := c.NegotiateFormat(bookContentTypes...)
+		if format == "" {
+			restErr := rest_errors.NewRestError(
+				"book representations available are "+strings.Join(bookContentTypes, ", "),
+				http.StatusNotAcceptable,
+				"not_acceptable",
+				nil,
+			)
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		c.Header("Vary", "Accept")
+		if format == gin.MIMEJSON {
+			writeWithETag(c, book.Book.Version, book)
+			return
+		}
+
+		biblioFormat, _ := biblio.FormatOf(format)
+		body, marshalErr := biblio.Marshal(*book, biblioFormat)
+		if marshalErr != nil {
+			restErr := rest_errors.NewInternalServerError(marshalErr.Error())
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+		writeBodyWithETag(c, book.Book.Version, format, body)
 	}
 }
 
+// bookContentTypes are the representations a book can be negotiated in, JSON
+// being the default, and the bibliographic ones library partners harvest
+var bookContentTypes = []string{
+	gin.MIMEJSON,
+	biblio.ContentType(biblio.FormatMARCXML),
+	biblio.ContentType(biblio.FormatMARC),
+	biblio.ContentType(biblio.FormatDublinCore),
+}
+
 func getPublisher(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		publisherID, idErr := strconv.ParseInt(c.Param("publisher_id"), 10, 64)
@@ -146,12 +301,365 @@ func getPublisher(br ports.BooksRepositoryInterface) gin.HandlerFunc {
 			return
 		}
 
-		publisher, err := br.GetPublisherById(publisherID)
+		publisher, err := br.GetPublisherById(c.Request.Context(), publisherID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		writeWithETag(c, publisher.Publisher.Version, publisher)
+	}
+}
+
+func updateBookStatus(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var request struct {
+			Status string `json:"status" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		version, err := expectedVersion(c)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		current, err := br.GetBookById(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		isSeller := authorizedUser.Id == current.Book.SellerID
+		if authorizedUser.Role != "admin" && (!isSeller || domain.RequiresReviewer(request.Status)) {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if current.Book.Status == domain.StatusPendingReview && request.Status == domain.StatusPublished {
+			restErr := rest_errors.NewBadRequestError("pending books are published through the moderation queue")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if current.Book.Version != version {
+			restErr := preconditionFailed()
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		book := current.Book
+		if err := book.TransitionTo(request.Status); err != nil {
+			restErr := rest_errors.NewBadRequestError(err.Error())
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if err := br.UpdateBookStatus(c.Request.Context(), authorizedUser.Id, bookID, version, current.Book.Status, book.Status); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		book.Version++
+		c.JSON(http.StatusOK, book)
+	}
+}
+
+func updateBookPrice(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var request struct {
+			Price int64 `json:"price"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil || request.Price < 0 {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		version, err := expectedVersion(c)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		current, err := br.GetBookById(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if !canManageBook(authorizedUser, current.Book) {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if err := br.UpdateBookPrice(c.Request.Context(), authorizedUser.Id, bookID, version, request.Price); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		// active promotions apply to the new price, the book is read back so
+		// the response carries the price buyers actually pay
+		book, err := br.GetBookById(c.Request.Context(), bookID)
 		if err != nil {
 			c.JSON(err.Status(), err)
 			return
 		}
+		writeWithETag(c, book.Book.Version, book)
+	}
+}
+
+func createPromotion(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
 
+		var promotion domain.Promotion
+		if err := c.ShouldBindJSON(&promotion); err != nil {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+		promotion.BookID = bookID
+
+		if err := promotion.Validate(); err != nil {
+			restErr := rest_errors.NewBadRequestError(err.Error())
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		current, err := br.GetBookById(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if !canManageBook(authorizedUser, current.Book) {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if err := br.SavePromotion(c.Request.Context(), authorizedUser.Id, &promotion); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+		c.JSON(http.StatusOK, promotion)
+	}
+}
+
+func getPriceHistory(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		// the history of a book is as visible as the book itself
+		book, err := br.GetBookById(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+		if !canSeeBook(c, book.Book) {
+			restErr := rest_errors.NewNotFoundError("book not found")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		history, err := br.GetPriceHistory(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+func updateAuthor(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorID, idErr := strconv.ParseInt(c.Param("author_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid author id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var author domain.Author
+		if err := c.ShouldBindJSON(&author); err != nil {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		version, err := expectedVersion(c)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		author.ID = authorID
+		author.Version = version
+		if err := br.UpdateAuthor(c.Request.Context(), authorizedUser.Id, &author); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+		c.JSON(http.StatusOK, author)
+	}
+}
+
+func updatePublisher(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		publisherID, idErr := strconv.ParseInt(c.Param("publisher_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid publisher id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var publisher domain.Publisher
+		if err := c.ShouldBindJSON(&publisher); err != nil {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		version, err := expectedVersion(c)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		publisher.ID = publisherID
+		publisher.Version = version
+		if err := br.UpdatePublisher(c.Request.Context(), authorizedUser.Id, &publisher); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
 		c.JSON(http.StatusOK, publisher)
 	}
 }
+
+func updateBook(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var book domain.Book
+		if err := c.ShouldBindJSON(&book); err != nil {
+			restErr := rest_errors.NewBadRequestError("invalid request")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		version, err := expectedVersion(c)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		current, err := br.GetBookById(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if !canManageBook(authorizedUser, current.Book) {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if current.Book.Version != version {
+			restErr := preconditionFailed()
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if err := validateBookUpdate(&book); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+		if book.ISBN != "" {
+			isbn, err := domain.NormalizeISBN(book.ISBN)
+			if err != nil {
+				restErr := rest_errors.NewBadRequestError(err.Error())
+				c.JSON(restErr.Status(), restErr)
+				return
+			}
+			book.ISBN = isbn
+		}
+
+		book.ID = bookID
+		book.Version = version
+		// what a seller changes in a public book is reviewed like a new
+		// submission, the book isn't public until it's approved again
+		book.Status = ""
+		if authorizedUser.Role != "admin" && domain.IsPublicStatus(current.Book.Status) {
+			book.Status = domain.StatusPendingReview
+		}
+		if err := br.UpdateBook(c.Request.Context(), authorizedUser.Id, &book); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+		c.JSON(http.StatusOK, book)
+	}
+}
+
+// requiresAuth turns down requests without a valid token, tracing the call
+// to the users service that validates it, and applies the caller's rate limit
+// once it's known
+func (s *Server) requiresAuth(handler gin.HandlerFunc) gin.HandlerFunc {
+	return tracing.Auth(ratelimit.PerUser(handler), func(next gin.HandlerFunc) gin.HandlerFunc {
+		return auth.RequiresAuth(next, s.oauthC.C)
+	})
+}
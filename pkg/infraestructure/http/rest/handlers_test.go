@@ -0,0 +1,334 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo serves the books it holds, the methods a test doesn't use panic
+type fakeRepo struct {
+	ports.BooksRepositoryInterface
+	books   map[int64]domain.Book
+	updated *domain.Book
+	listed  domain.ListOptions
+	// filtered is the filter the books of a seller were last listed with
+	filtered *domain.SellerBooksFilter
+	// promotions are active on every book
+	promotions []domain.Promotion
+}
+
+func (r *fakeRepo) GetBookById(_ context.Context, id int64) (*domain.BookDenormalized, rest_errors.RestErr) {
+	book, ok := r.books[id]
+	if !ok {
+		return nil, rest_errors.NewNotFoundError("book not found")
+	}
+	return &domain.BookDenormalized{
+		Book:           book,
+		Promotions:     r.promotions,
+		EffectivePrice: domain.EffectivePrice(book.Price, r.promotions),
+	}, nil
+}
+
+func (r *fakeRepo) GetPriceHistory(context.Context, int64) ([]domain.PriceChange, rest_errors.RestErr) {
+	return []domain.PriceChange{{Price: 1999, ChangedAt: "2021-12-20 10:00:00"}}, nil
+}
+
+func (r *fakeRepo) UpdateBook(_ context.Context, _ int64, book *domain.Book) rest_errors.RestErr {
+	r.updated = book
+	return nil
+}
+
+func (r *fakeRepo) UpdateBookPrice(_ context.Context, _ int64, bookID int64, version int64, price int64) rest_errors.RestErr {
+	book := r.books[bookID]
+	if book.Version != version {
+		return preconditionFailed()
+	}
+	book.Price = price
+	book.Version++
+	r.books[bookID] = book
+	return nil
+}
+
+func (r *fakeRepo) SavePromotion(_ context.Context, _ int64, promotion *domain.Promotion) rest_errors.RestErr {
+	promotion.ID = 1
+	r.promotions = append(r.promotions, *promotion)
+	return nil
+}
+
+func (r *fakeRepo) ListBooks(_ context.Context, opts domain.ListOptions) ([]domain.Book, rest_errors.RestErr) {
+	r.listed = opts
+	var books []domain.Book
+	for _, book := range r.books {
+		books = append(books, book)
+	}
+	return books, nil
+}
+
+func (r *fakeRepo) ListSellerBooks(_ context.Context, filter domain.SellerBooksFilter, opts domain.ListOptions) ([]domain.Book, rest_errors.RestErr) {
+	r.filtered = &filter
+	r.listed = opts
+	var books []domain.Book
+	for _, book := range r.books {
+		if book.SellerID == filter.SellerID {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+// serve runs handler on req as user, nil serving it to an anonymous caller
+func serve(handler gin.HandlerFunc, req *http.Request, user *auth.UserPayload, params ...gin.Param) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = req
+	c.Params = params
+	if user != nil {
+		c.Set("user_payload", *user)
+	}
+	handler(c)
+	return rec
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	repo := &fakeRepo{books: map[int64]domain.Book{
+		1: {ID: 1, SellerID: 7, Status: domain.StatusPublished},
+		2: {ID: 2, SellerID: 7, Status: domain.StatusDraft},
+	}}
+	get := func(bookID string, user *auth.UserPayload) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/books/"+bookID+"/price-history", nil)
+		return serve(getPriceHistory(repo), req, user, gin.Param{Key: "book_id", Value: bookID}).Code
+	}
+
+	t.Run("Published", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, get("1", nil))
+	})
+
+	t.Run("DraftToAnonymous", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusNotFound, get("2", nil))
+	})
+
+	t.Run("DraftToAnotherUser", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusNotFound, get("2", &auth.UserPayload{Id: 8}))
+	})
+
+	t.Run("DraftToSeller", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, get("2", &auth.UserPayload{Id: 7}))
+	})
+
+	t.Run("DraftToAdmin", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, get("2", &auth.UserPayload{Id: 1, Role: "admin"}))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusNotFound, get("3", nil))
+	})
+}
+
+func TestUpdateBook(t *testing.T) {
+	seller := &auth.UserPayload{Id: 7}
+	put := func(repo *fakeRepo, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/books/1", strings.NewReader(body))
+		req.Header.Set("If-Match", `"3"`)
+		return serve(updateBook(repo), req, seller, gin.Param{Key: "book_id", Value: "1"})
+	}
+	newRepo := func() *fakeRepo {
+		return &fakeRepo{books: map[int64]domain.Book{
+			1: {ID: 1, ISBN: "9780802130303", SellerID: 7, Status: domain.StatusPublished, Version: 3},
+		}}
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2,"author_id":[3,4]}`)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, "Ficciones", repo.updated.Title)
+		assert.EqualValues(t, 3, repo.updated.Version)
+	})
+
+	t.Run("HyphenatedISBN", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"isbn":"978-0-8021-3030-3","title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2}`)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, "9780802130303", repo.updated.ISBN)
+	})
+
+	t.Run("InvalidISBN", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"isbn":"9780802130304","title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2}`)
+
+		assert.EqualValues(t, http.StatusBadRequest, rec.Code)
+		assert.Nil(t, repo.updated)
+	})
+
+	t.Run("NoISBN", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2}`)
+
+		// the repository keeps the ISBN the book has
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.Empty(t, repo.updated.ISBN)
+	})
+
+	t.Run("MissingFields", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"title":"Ficciones"}`)
+
+		assert.EqualValues(t, http.StatusBadRequest, rec.Code)
+		for _, cause := range []string{
+			"original_release must be a YYYY-MM-DD date",
+			"published must be a YYYY-MM-DD date",
+			"publisher_id is required",
+		} {
+			assert.Contains(t, rec.Body.String(), cause)
+		}
+		assert.Nil(t, repo.updated)
+	})
+
+	t.Run("SellerEditsPublicBook", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2}`)
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, domain.StatusPendingReview, repo.updated.Status)
+	})
+
+	t.Run("SellerEditsDraft", func(t *testing.T) {
+		repo := newRepo()
+		book := repo.books[1]
+		book.Status = domain.StatusDraft
+		repo.books[1] = book
+
+		rec := put(repo, `{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2,"status":"pending_review"}`)
+
+		// the status is kept, drafts are submitted through their own route
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.Empty(t, repo.updated.Status)
+	})
+
+	t.Run("AdminEditsPublicBook", func(t *testing.T) {
+		repo := newRepo()
+		req := httptest.NewRequest(http.MethodPut, "/v1/books/1", strings.NewReader(
+			`{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2}`,
+		))
+		req.Header.Set("If-Match", `"3"`)
+
+		rec := serve(updateBook(repo), req, &auth.UserPayload{Id: 1, Role: "admin"}, gin.Param{Key: "book_id", Value: "1"})
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.Empty(t, repo.updated.Status)
+	})
+
+	t.Run("StaleVersion", func(t *testing.T) {
+		repo := newRepo()
+		book := repo.books[1]
+		book.Version = 4
+		repo.books[1] = book
+
+		rec := put(repo, `{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2}`)
+
+		assert.EqualValues(t, http.StatusPreconditionFailed, rec.Code)
+		assert.Nil(t, repo.updated)
+	})
+
+	t.Run("RepeatedAuthor", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, `{"title":"Ficciones","original_release":"1944-01-01","published":"1962-01-01","publisher_id":2,"author_id":[3,3]}`)
+
+		assert.EqualValues(t, http.StatusBadRequest, rec.Code)
+		assert.Nil(t, repo.updated)
+	})
+}
+
+func TestUpdateBookPrice(t *testing.T) {
+	put := func(repo *fakeRepo, user *auth.UserPayload) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/books/1/price", strings.NewReader(`{"price":2000}`))
+		req.Header.Set("If-Match", `"3"`)
+		return serve(updateBookPrice(repo), req, user, gin.Param{Key: "book_id", Value: "1"})
+	}
+	newRepo := func() *fakeRepo {
+		return &fakeRepo{
+			books:      map[int64]domain.Book{1: {ID: 1, SellerID: 7, Price: 1999, Status: domain.StatusPublished, Version: 3}},
+			promotions: []domain.Promotion{{Kind: domain.PromotionPercentage, Amount: 10}},
+		}
+	}
+
+	t.Run("Seller", func(t *testing.T) {
+		repo := newRepo()
+
+		rec := put(repo, &auth.UserPayload{Id: 7})
+
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, 2000, repo.books[1].Price)
+		assert.True(t, strings.HasPrefix(rec.Header().Get("ETag"), `"4-`), rec.Header().Get("ETag"))
+		// the response is the book as read back, with the promotion applied
+		assert.Contains(t, rec.Body.String(), `"price":2000`)
+		assert.Contains(t, rec.Body.String(), `"effective_price":1800`)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, put(newRepo(), &auth.UserPayload{Id: 1, Role: "admin"}).Code)
+	})
+
+	t.Run("AnotherUser", func(t *testing.T) {
+		repo := newRepo()
+
+		assert.EqualValues(t, http.StatusUnauthorized, put(repo, &auth.UserPayload{Id: 8}).Code)
+		assert.EqualValues(t, 1999, repo.books[1].Price)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := &fakeRepo{books: map[int64]domain.Book{}}
+
+		assert.EqualValues(t, http.StatusNotFound, put(repo, &auth.UserPayload{Id: 7}).Code)
+	})
+}
+
+func TestCreatePromotion(t *testing.T) {
+	post := func(repo *fakeRepo, user *auth.UserPayload) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/books/1/promotions", strings.NewReader(
+			`{"kind":"fixed","amount":500,"starts_at":"2022-01-01 00:00:00","ends_at":"2022-02-01 00:00:00"}`,
+		))
+		return serve(createPromotion(repo), req, user, gin.Param{Key: "book_id", Value: "1"}).Code
+	}
+	newRepo := func() *fakeRepo {
+		return &fakeRepo{books: map[int64]domain.Book{1: {ID: 1, SellerID: 7, Status: domain.StatusPublished}}}
+	}
+
+	t.Run("Seller", func(t *testing.T) {
+		repo := newRepo()
+
+		assert.EqualValues(t, http.StatusOK, post(repo, &auth.UserPayload{Id: 7}))
+		assert.Len(t, repo.promotions, 1)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, post(newRepo(), &auth.UserPayload{Id: 1, Role: "admin"}))
+	})
+
+	t.Run("AnotherUser", func(t *testing.T) {
+		repo := newRepo()
+
+		assert.EqualValues(t, http.StatusUnauthorized, post(repo, &auth.UserPayload{Id: 8}))
+		assert.Empty(t, repo.promotions)
+	})
+}
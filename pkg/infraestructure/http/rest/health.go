@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check probes a dependency the service can't work without
+type Check struct {
+	Name  string
+	Probe func(context.Context) error
+	// Note tells what the probe can't vouch for, it's reported along with
+	// the check's result
+	Note string
+}
+
+type checkResult struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+type readiness struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// getHealth tells the process is alive, it doesn't look at any dependency so
+// an outage of one of them doesn't get the service restarted
+func getHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// getReadiness runs every check at once, each within timeout, and answers 503
+// when any of them fails so no traffic is routed to the service. While the
+// service is shutting down it's never ready.
+func getReadiness(checks []Check, timeout time.Duration, draining func() bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if draining() {
+			c.JSON(http.StatusServiceUnavailable, readiness{Status: "draining", Checks: map[string]checkResult{}})
+			return
+		}
+
+		report := readiness{Status: "ready", Checks: make(map[string]checkResult, len(checks))}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, check := range checks {
+			wg.Add(1)
+			go func(check Check) {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+				defer cancel()
+
+				start := time.Now()
+				err := check.Probe(ctx)
+				result := checkResult{Status: "up", LatencyMS: time.Since(start).Milliseconds(), Note: check.Note}
+				if err != nil {
+					result.Status, result.Error = "down", err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				report.Checks[check.Name] = result
+				if err != nil {
+					report.Status = "unavailable"
+				}
+			}(check)
+		}
+		wg.Wait()
+
+		status := http.StatusOK
+		if report.Status != "ready" {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
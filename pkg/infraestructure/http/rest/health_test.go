@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	up := Check{Name: "mysql", Probe: func(context.Context) error { return nil }}
+	hanging := Check{Name: "auth", Note: "probes the address only", Probe: func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("connection to auth:10000 is CONNECTING")
+	}}
+
+	draining := false
+	ready := func(checks ...Check) (int, readiness) {
+		router := gin.New()
+		router.GET("/readyz", getReadiness(checks, 10*time.Millisecond, func() bool { return draining }))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var report readiness
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		return rec.Code, report
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		status, report := ready(up)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, "ready", report.Status)
+		assert.EqualValues(t, "up", report.Checks["mysql"].Status)
+		assert.Empty(t, report.Checks["mysql"].Note)
+	})
+
+	t.Run("DependencyDown", func(t *testing.T) {
+		status, report := ready(up, hanging)
+
+		assert.EqualValues(t, http.StatusServiceUnavailable, status)
+		assert.EqualValues(t, "unavailable", report.Status)
+		assert.EqualValues(t, "up", report.Checks["mysql"].Status)
+		assert.EqualValues(t, "down", report.Checks["auth"].Status)
+		assert.EqualValues(t, "connection to auth:10000 is CONNECTING", report.Checks["auth"].Error)
+		assert.EqualValues(t, "probes the address only", report.Checks["auth"].Note)
+	})
+	t.Run("Draining", func(t *testing.T) {
+		draining = true
+		defer func() { draining = false }()
+
+		status, report := ready(up)
+
+		assert.EqualValues(t, http.StatusServiceUnavailable, status)
+		assert.EqualValues(t, "draining", report.Status)
+	})
+}
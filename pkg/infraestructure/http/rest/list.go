@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// parseListOptions reads the after_id and limit query parameters the list
+// routes page with
+func parseListOptions(c *gin.Context) (domain.ListOptions, rest_errors.RestErr) {
+	var opts domain.ListOptions
+
+	if afterID := c.Query("after_id"); afterID != "" {
+		id, err := strconv.ParseInt(afterID, 10, 64)
+		if err != nil {
+			return opts, rest_errors.NewBadRequestError("invalid after_id")
+		}
+		opts.AfterID = id
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return opts, rest_errors.NewBadRequestError("invalid limit")
+		}
+		opts.Limit = l
+	}
+
+	return opts.Normalize(), nil
+}
+
+func listAuthors(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, restErr := parseListOptions(c)
+		if restErr != nil {
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		authors, err := br.ListAuthors(c.Request.Context(), opts)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, authors)
+	}
+}
+
+// listBooks lists the public books, or those of the seller_id given. Sellers
+// listing their own books, and admins, also get the ones that aren't public,
+// which status narrows down to the ones in a status.
+func listBooks(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, restErr := parseListOptions(c)
+		if restErr != nil {
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		status := c.Query("status")
+		if status != "" && !domain.IsValidStatus(status) {
+			restErr := rest_errors.NewBadRequestError("invalid status")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if c.Query("seller_id") == "" {
+			if status != "" {
+				restErr := rest_errors.NewBadRequestError("status can only filter the books of a seller_id")
+				c.JSON(restErr.Status(), restErr)
+				return
+			}
+
+			books, err := br.ListBooks(c.Request.Context(), opts)
+			if err != nil {
+				c.JSON(err.Status(), err)
+				return
+			}
+
+			c.JSON(http.StatusOK, books)
+			return
+		}
+
+		sellerID, idErr := strconv.ParseInt(c.Query("seller_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid seller_id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		filter := domain.SellerBooksFilter{SellerID: sellerID, Status: status, PublicOnly: true}
+		if payload, exists := c.Get("user_payload"); exists {
+			filter.PublicOnly = !canManageBook(payload.(auth.UserPayload), domain.Book{SellerID: sellerID})
+		}
+		if filter.PublicOnly && status != "" && !domain.IsPublicStatus(status) {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		books, err := br.ListSellerBooks(c.Request.Context(), filter, opts)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, books)
+	}
+}
+
+func listPublishers(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, restErr := parseListOptions(c)
+		if restErr != nil {
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		publishers, err := br.ListPublishers(c.Request.Context(), opts)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, publishers)
+	}
+}
@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListBooks(t *testing.T) {
+	list := func(query string) (*fakeRepo, int) {
+		repo := &fakeRepo{books: map[int64]domain.Book{1: {ID: 1, Status: domain.StatusPublished}}}
+		req := httptest.NewRequest(http.MethodGet, "/v1/books"+query, nil)
+		return repo, serve(listBooks(repo), req, nil).Code
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		repo, code := list("?after_id=20&limit=5")
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.EqualValues(t, domain.ListOptions{AfterID: 20, Limit: 5}, repo.listed)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		repo, code := list("")
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.EqualValues(t, domain.ListOptions{Limit: domain.DefaultListLimit}, repo.listed)
+	})
+
+	t.Run("LimitOverMax", func(t *testing.T) {
+		repo, code := list("?limit=1000")
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.EqualValues(t, domain.MaxListLimit, repo.listed.Limit)
+	})
+
+	t.Run("InvalidAfterID", func(t *testing.T) {
+		_, code := list("?after_id=abc")
+		assert.EqualValues(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		_, code := list("?limit=ten")
+		assert.EqualValues(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestListSellerBooks(t *testing.T) {
+	list := func(query string, user *auth.UserPayload) (*fakeRepo, int) {
+		repo := &fakeRepo{books: map[int64]domain.Book{
+			1: {ID: 1, SellerID: 7, Status: domain.StatusPublished},
+			2: {ID: 2, SellerID: 7, Status: domain.StatusDraft},
+		}}
+		req := httptest.NewRequest(http.MethodGet, "/v1/books"+query, nil)
+		return repo, serve(listBooks(repo), req, user).Code
+	}
+
+	t.Run("Anonymous", func(t *testing.T) {
+		repo, code := list("?seller_id=7", nil)
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.EqualValues(t, &domain.SellerBooksFilter{SellerID: 7, PublicOnly: true}, repo.filtered)
+	})
+
+	t.Run("AnotherUser", func(t *testing.T) {
+		repo, code := list("?seller_id=7", &auth.UserPayload{Id: 8})
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.True(t, repo.filtered.PublicOnly)
+	})
+
+	t.Run("Seller", func(t *testing.T) {
+		repo, code := list("?seller_id=7&status=draft&limit=5", &auth.UserPayload{Id: 7})
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.EqualValues(t, &domain.SellerBooksFilter{SellerID: 7, Status: domain.StatusDraft}, repo.filtered)
+		assert.EqualValues(t, 5, repo.listed.Limit)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		repo, code := list("?seller_id=7&status=draft", &auth.UserPayload{Id: 1, Role: "admin"})
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.False(t, repo.filtered.PublicOnly)
+	})
+
+	t.Run("DraftsOfAnotherSeller", func(t *testing.T) {
+		repo, code := list("?seller_id=7&status=draft", &auth.UserPayload{Id: 8})
+		assert.EqualValues(t, http.StatusUnauthorized, code)
+		assert.Nil(t, repo.filtered)
+	})
+
+	t.Run("PublicStatusOfAnotherSeller", func(t *testing.T) {
+		repo, code := list("?seller_id=7&status=out_of_print", nil)
+		assert.EqualValues(t, http.StatusOK, code)
+		assert.EqualValues(t, domain.StatusOutOfPrint, repo.filtered.Status)
+	})
+
+	t.Run("StatusWithoutSeller", func(t *testing.T) {
+		_, code := list("?status=published", nil)
+		assert.EqualValues(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		_, code := list("?seller_id=7&status=sold", &auth.UserPayload{Id: 7})
+		assert.EqualValues(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("InvalidSellerID", func(t *testing.T) {
+		_, code := list("?seller_id=seven", nil)
+		assert.EqualValues(t, http.StatusBadRequest, code)
+	})
+}
@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+func getPendingBooks(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		submissions, err := br.GetPendingBooks(c.Request.Context())
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, submissions)
+	}
+}
+
+func getModerations(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		moderations, err := br.GetModerations(c.Request.Context(), bookID)
+		if err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, moderations)
+	}
+}
+
+func moderateBook(br ports.BooksRepositoryInterface, decision string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID, idErr := strconv.ParseInt(c.Param("book_id"), 10, 64)
+		if idErr != nil {
+			restErr := rest_errors.NewBadRequestError("invalid book id")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		var request struct {
+			Reason string `json:"reason"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				restErr := rest_errors.NewBadRequestError("invalid request")
+				c.JSON(restErr.Status(), restErr)
+				return
+			}
+		}
+
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		moderation := domain.Moderation{
+			BookID:      bookID,
+			ModeratorID: authorizedUser.Id,
+			Decision:    decision,
+			Reason:      request.Reason,
+		}
+		if err := moderation.Validate(); err != nil {
+			restErr := rest_errors.NewBadRequestError(err.Error())
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		if err := br.ModerateBook(c.Request.Context(), &moderation); err != nil {
+			c.JSON(err.Status(), err)
+			return
+		}
+		c.JSON(http.StatusOK, moderation)
+	}
+}
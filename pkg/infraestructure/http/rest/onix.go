@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/onix"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// maxONIXUpload bounds the size of uploaded ONIX messages, bigger feeds are
+// meant to be ingested through the command line
+const maxONIXUpload = 64 << 20
+
+// ingestONIX takes the message either as the request body or as the file
+// field of a multipart form
+func ingestONIX(br ports.BooksRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizedUser := c.MustGet("user_payload").(auth.UserPayload)
+		if authorizedUser.Role != "admin" {
+			restErr := rest_errors.NewUnauthorizedError("you don't have the permissions to access this resource")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		dryRun, parseErr := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+		if parseErr != nil {
+			restErr := rest_errors.NewBadRequestError("dry_run must be a boolean")
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxONIXUpload)
+
+		var message io.Reader = c.Request.Body
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			file, err := c.FormFile("file")
+			if err != nil {
+				restErr := rest_errors.NewBadRequestError("the ONIX message is expected in the file field")
+				c.JSON(restErr.Status(), restErr)
+				return
+			}
+			opened, err := file.Open()
+			if err != nil {
+				restErr := rest_errors.NewInternalServerError(err.Error())
+				c.JSON(restErr.Status(), restErr)
+				return
+			}
+			defer opened.Close()
+			message = opened
+		}
+
+		ingester := onix.Ingester{
+			Repo:     br,
+			ActorID:  authorizedUser.Id,
+			Currency: c.DefaultQuery("currency", onix.DefaultCurrency),
+			DryRun:   dryRun,
+		}
+
+		report, err := ingester.Ingest(c.Request.Context(), message)
+		if err != nil {
+			restErr := rest_errors.NewRestError("invalid ONIX message: "+err.Error(), http.StatusBadRequest, "bad_request", []interface{}{report})
+			c.JSON(restErr.Status(), restErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -3,48 +3,95 @@ package rest
 import (
 	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"sync/atomic"
+	"time"
 
-	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/repositories"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/clients"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/metrics"
 	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	db     *sql.DB
-	srv    *http.Server
-	oauthC *auth.Client
+	srv     *http.Server
+	oauthC  *auth.Client
+	metrics *metrics.Metrics
+	logger  zerolog.Logger
+
+	trustedProxies []string
+	limiters       limiters
+	cors           config.CORS
+	hstsMaxAge     time.Duration
+
+	checks           []Check
+	readinessTimeout time.Duration
+
+	// draining is set once Stop is called, from then on readiness fails
+	draining   int32
+	drainDelay time.Duration
 }
 
-func NewServer(srv *http.Server, db *sql.DB, oc *auth.Client) *Server {
+// NewServer builds the REST server on top of br, db and authConn, the
+// connection oc validates tokens through, are only used to tell whether MySQL
+// and the users service are reachable
+func NewServer(cfg config.HTTP, db *sql.DB, br ports.BooksRepositoryInterface, oc *auth.Client, authConn *grpc.ClientConn, m *metrics.Metrics, logger zerolog.Logger) *Server {
 	server := &Server{
-		db:     db,
-		srv:    srv,
-		oauthC: oc,
+		srv: &http.Server{
+			Addr:              cfg.Addr,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		},
+		oauthC:         oc,
+		metrics:        m,
+		logger:         logger,
+		trustedProxies: cfg.TrustedProxies,
+		limiters:       newLimiters(cfg.RateLimits),
+		cors:           cfg.CORS,
+		hstsMaxAge:     cfg.HSTSMaxAge,
+		checks: []Check{
+			{Name: "mysql", Probe: db.PingContext},
+			{Name: "auth", Probe: func(ctx context.Context) error {
+				return clients.Reachable(ctx, authConn)
+			}},
+		},
+		readinessTimeout: cfg.ReadinessTimeout,
+		drainDelay:       cfg.DrainDelay,
 	}
 
-	bookrepo := repositories.NewBooksRepo(db)
-
-	router := server.handler(bookrepo)
+	router := server.handler(br)
 
 	server.srv.Handler = router
 	return server
 }
 
-func (s *Server) Start() {
-	if err := s.srv.ListenAndServe(); err != nil {
-		log.Fatalf("error while serving: %v", err)
+// Start serves until the server is stopped, it only returns an error when
+// serving failed
+func (s *Server) Start() error {
+	if err := s.srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error while serving: %w", err)
 	}
+	return nil
 }
 
-func (s *Server) Stop(ctx context.Context) {
-	s.db.Close()
+// Stop fails readiness and keeps serving for the drain delay, then stops
+// accepting requests and waits for the in-flight ones until ctx is done.
+// Dependencies the server uses are closed by its owner once Stop returns.
+func (s *Server) Stop(ctx context.Context) error {
+	atomic.StoreInt32(&s.draining, 1)
 
-	go func() {
-		if err := s.srv.Shutdown(ctx); err != nil {
-			log.Fatal("Server Shutdown:", err)
-		}
-	}()
+	select {
+	case <-time.After(s.drainDelay):
+	case <-ctx.Done():
+	}
+
+	return s.srv.Shutdown(ctx)
+}
 
-	log.Println("Server exiting")
+func (s *Server) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
 }
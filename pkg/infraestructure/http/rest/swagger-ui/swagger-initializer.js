@@ -0,0 +1,7 @@
+window.onload = () => {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    validatorUrl: null,
+  });
+};
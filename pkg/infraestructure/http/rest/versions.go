@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/http/gql"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// legacySunset is when the unversioned aliases of the v1 routes stop being served
+var legacySunset = time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+// routesV1 registers the v1 API on rg.
+//
+// Every version owns its routes function, a future routesV2 registers the
+// handlers whose payloads changed next to the v1 ones it keeps, all of them
+// built on top of the same repository.
+func (s *Server) routesV1(rg *gin.RouterGroup, br ports.BooksRepositoryInterface) {
+	reads := rg.Group("", s.limiters.read.Middleware())
+	reads.GET("/authors", listAuthors(br))
+	reads.GET("/books", s.optionalAuth(listBooks(br)))
+	reads.GET("/publishers", listPublishers(br))
+
+	reads.GET("/authors/:author_id", getAuthor(br))
+	reads.GET("/books/:book_id", s.optionalAuth(getBook(br)))
+	reads.GET("/publishers/:publisher_id", getPublisher(br))
+	reads.GET("/books/:book_id/price-history", s.optionalAuth(getPriceHistory(br)))
+
+	writes := rg.Group("", s.limiters.write.Middleware())
+	// the schema has mutations, which can't be told from queries without
+	// parsing the body, so the whole endpoint takes the limits of writes
+	writes.POST("/graphql", s.optionalAuth(gql.Handler(br)))
+	writes.POST("/authors", s.requiresAuth(createAuthor(br)))
+	writes.POST("/publishers", s.requiresAuth(createPublisher(br)))
+	writes.POST("/books", s.requiresAuth(createBook(br)))
+	writes.PUT("/authors/:author_id", s.requiresAuth(updateAuthor(br)))
+	writes.PUT("/publishers/:publisher_id", s.requiresAuth(updatePublisher(br)))
+	writes.PUT("/books/:book_id", s.requiresAuth(updateBook(br)))
+	writes.PUT("/books/:book_id/status", s.requiresAuth(updateBookStatus(br)))
+	writes.GET("/moderation/books", s.requiresAuth(getPendingBooks(br)))
+	writes.GET("/moderation/books/:book_id", s.requiresAuth(getModerations(br)))
+	writes.POST("/moderation/books/:book_id/approve", s.requiresAuth(moderateBook(br, domain.DecisionApproved)))
+	writes.POST("/moderation/books/:book_id/reject", s.requiresAuth(moderateBook(br, domain.DecisionRejected)))
+
+	writes.GET("/audit", s.requiresAuth(getAuditLog(br)))
+
+	writes.PUT("/books/:book_id/price", s.requiresAuth(updateBookPrice(br)))
+	writes.POST("/books/:book_id/promotions", s.requiresAuth(createPromotion(br)))
+
+	bulk := rg.Group("", s.limiters.bulk.Middleware())
+	bulk.POST("/books/bulk", s.requiresAuth(createBooks(br)))
+	bulk.GET("/export/books", s.requiresAuth(exportBooks(br)))
+	bulk.POST("/import/onix", s.requiresAuth(ingestONIX(br)))
+}
+
+// limiters are shared by every version of the API, so a caller has the same
+// limits whichever version it calls
+type limiters struct {
+	read, write, bulk *ratelimit.Limiter
+}
+
+func newLimiters(cfg config.RateLimits) limiters {
+	return limiters{
+		read:  ratelimit.New(cfg.Read),
+		write: ratelimit.New(cfg.Write),
+		bulk:  ratelimit.New(cfg.Bulk),
+	}
+}
+
+// deprecated flags responses as coming from a deprecated route and points
+// clients to the same path under successorPrefix
+func deprecated(successorPrefix string, sunset time.Time) gin.HandlerFunc {
+	sunsetHeader := sunset.Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Sunset", sunsetHeader)
+		c.Header("Link", "<"+successorPrefix+c.Request.URL.Path+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
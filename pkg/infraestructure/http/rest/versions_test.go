@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/metrics"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	router := testRouter(t)
+
+	t.Run("Legacy", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/books/not-an-id", nil))
+
+		assert.EqualValues(t, http.StatusBadRequest, rec.Code)
+		assert.EqualValues(t, "true", rec.Header().Get("Deprecation"))
+		assert.EqualValues(t, legacySunset.Format(http.TimeFormat), rec.Header().Get("Sunset"))
+		assert.EqualValues(t, `</v1/books/not-an-id>; rel="successor-version"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("V1", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/books/not-an-id", nil))
+
+		assert.EqualValues(t, http.StatusBadRequest, rec.Code)
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+		assert.Empty(t, rec.Header().Get("Sunset"))
+	})
+}
+
+// mutations go through /graphql as well, so it's limited as writes are
+func TestGraphQLTakesWriteLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &Server{oauthC: &auth.Client{}, metrics: metrics.New(), logger: zerolog.Nop(), limiters: newLimiters(config.RateLimits{
+		Read:  config.RateLimit{Anonymous: config.Rate{Requests: 10, Period: time.Minute}},
+		Write: config.RateLimit{Anonymous: config.Rate{Requests: 1, Period: time.Minute}},
+	})}
+	router := s.handler(&fakeRepo{})
+
+	call := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(`{"query":"{ __typename }"}`)))
+		return rec
+	}
+
+	assert.EqualValues(t, "1", call().Header().Get("X-RateLimit-Limit"))
+	assert.EqualValues(t, http.StatusTooManyRequests, call().Code)
+}
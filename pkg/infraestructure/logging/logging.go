@@ -0,0 +1,72 @@
+// Package logging sets up the service's structured JSON logs. Every request
+// gets a logger of its own, tagged with the request's ID, which is carried in
+// the request's context.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	stdlog "log"
+	"os"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/rs/zerolog"
+)
+
+// RequestIDHeader carries the request's ID, it's taken from the request when
+// the caller sends one and echoed in the response
+const RequestIDHeader = "X-Request-ID"
+
+// New returns the service's logger, writing JSON lines to stdout. Standard
+// library log calls are routed through it too, so every line is JSON.
+func New(cfg config.Log) zerolog.Logger {
+	return newLogger(os.Stdout, cfg)
+}
+
+func newLogger(w io.Writer, cfg config.Log) zerolog.Logger {
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	zerolog.DurationFieldUnit = time.Millisecond
+
+	level, err := zerolog.ParseLevel(cfg.Level)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+
+	logger := zerolog.New(w).Level(level).With().Timestamp().Str("service", "books-api").Logger()
+
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(logger)
+
+	return logger
+}
+
+// FromContext returns the logger of the request ctx belongs to, or one that
+// discards everything when there's none
+func FromContext(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
+}
+
+// validRequestID keeps IDs sent by callers short and printable, as they end up
+// in every log line of the request
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id[:])
+}
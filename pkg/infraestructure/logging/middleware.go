@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware gives every request an ID and a logger carrying it, then logs
+// the request once it's served. It must come before any handler that logs.
+func Middleware(base zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		fields := base.With().Str("request_id", requestID)
+		// tracing.Middleware runs first, so log lines can be matched to traces
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			fields = fields.Str("trace_id", span.TraceID().String())
+		}
+		logger := fields.Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		event := logger.Info()
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = logger.Error()
+		case status >= http.StatusBadRequest:
+			event = logger.Warn()
+		}
+
+		event = event.
+			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Int("bytes", c.Writer.Size())
+		if payload, ok := c.Get("user_payload"); ok {
+			if user, ok := payload.(auth.UserPayload); ok {
+				event = event.Int64("user_id", user.Id)
+			}
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+		event.Msg("request served")
+	}
+}
+
+// Recovery answers 500 to requests whose handler panicked, logging the panic
+// with the request's logger
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		FromContext(c.Request.Context()).Error().Interface("panic", recovered).Msg("handler panicked")
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
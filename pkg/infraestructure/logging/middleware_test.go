@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(Middleware(newLogger(&out, config.Log{Level: "info"})), Recovery())
+	router.GET("/books/:book_id", func(c *gin.Context) {
+		c.Set("user_payload", auth.UserPayload{Id: 42, Role: "admin"})
+		FromContext(c.Request.Context()).Info().Msg("loading book")
+		c.Status(http.StatusNotFound)
+	})
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	lines := func() []map[string]interface{} {
+		var entries []map[string]interface{}
+		decoder := json.NewDecoder(&out)
+		for decoder.More() {
+			var entry map[string]interface{}
+			assert.Nil(t, decoder.Decode(&entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	t.Run("PropagatesRequestID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/books/7", nil)
+		req.Header.Set(RequestIDHeader, "checkout-1234")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.EqualValues(t, "checkout-1234", rec.Header().Get(RequestIDHeader))
+
+		entries := lines()
+		assert.Len(t, entries, 2)
+		assert.EqualValues(t, "loading book", entries[0]["message"])
+		assert.EqualValues(t, "checkout-1234", entries[0]["request_id"])
+
+		served := entries[1]
+		assert.EqualValues(t, "warn", served["level"])
+		assert.EqualValues(t, "checkout-1234", served["request_id"])
+		assert.EqualValues(t, "/books/:book_id", served["route"])
+		assert.EqualValues(t, 404, served["status"])
+		assert.EqualValues(t, 42, served["user_id"])
+	})
+
+	t.Run("AssignsRequestID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/books/7", nil)
+		req.Header.Set(RequestIDHeader, "not valid\n")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Regexp(t, `^[0-9a-f]{32}$`, rec.Header().Get(RequestIDHeader))
+		lines()
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		assert.EqualValues(t, http.StatusInternalServerError, rec.Code)
+
+		entries := lines()
+		assert.Len(t, entries, 2)
+		assert.EqualValues(t, "boom", entries[0]["panic"])
+		assert.EqualValues(t, "error", entries[1]["level"])
+	})
+}
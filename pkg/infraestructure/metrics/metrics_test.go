@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := New()
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/books/:book_id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/books/1", "/books/2", "/authors"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.EqualValues(t, 2, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/books/:book_id", "200")))
+	assert.EqualValues(t, 1, testutil.ToFloat64(m.requests.WithLabelValues("GET", "unmatched", "404")))
+	assert.EqualValues(t, 2, testutil.CollectAndCount(m.requestDuration))
+}
+
+// fakeRepo implements the part of the repository the tests call, calling
+// anything else panics
+type fakeRepo struct {
+	ports.BooksRepositoryInterface
+}
+
+func (fakeRepo) GetBookById(ctx context.Context, id int64) (*domain.BookDenormalized, rest_errors.RestErr) {
+	if id == 0 {
+		return nil, rest_errors.NewNotFoundError("book not found")
+	}
+	return &domain.BookDenormalized{Book: domain.Book{ID: id}}, nil
+}
+
+func TestRepository(t *testing.T) {
+	m := New()
+	br := m.Repository(fakeRepo{})
+
+	book, err := br.GetBookById(context.Background(), 7)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 7, book.Book.ID)
+
+	_, err = br.GetBookById(context.Background(), 0)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	assert.EqualValues(t, 1, testutil.CollectAndCount(m.callDuration))
+	assert.EqualValues(t, 1, testutil.ToFloat64(m.callErrors.WithLabelValues("GetBookById", "404")))
+}
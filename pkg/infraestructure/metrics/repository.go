@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// repository times every call to the repository it wraps and counts the ones
+// that fail
+type repository struct {
+	next    ports.BooksRepositoryInterface
+	metrics *Metrics
+}
+
+// Repository instruments br, it's meant to wrap the repository every API is
+// built on so all of them are measured
+func (m *Metrics) Repository(br ports.BooksRepositoryInterface) ports.BooksRepositoryInterface {
+	return &repository{next: br, metrics: m}
+}
+
+func (r *repository) observe(method string, start time.Time, err *rest_errors.RestErr) {
+	r.metrics.callDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		r.metrics.callErrors.WithLabelValues(method, strconv.Itoa((*err).Status())).Inc()
+	}
+}
+
+func (r *repository) SaveAuthor(ctx context.Context, actorID int64, author *domain.Author) (err rest_errors.RestErr) {
+	defer r.observe("SaveAuthor", time.Now(), &err)
+	return r.next.SaveAuthor(ctx, actorID, author)
+}
+
+func (r *repository) UpdateAuthor(ctx context.Context, actorID int64, author *domain.Author) (err rest_errors.RestErr) {
+	defer r.observe("UpdateAuthor", time.Now(), &err)
+	return r.next.UpdateAuthor(ctx, actorID, author)
+}
+
+func (r *repository) GetAuthorById(ctx context.Context, id int64) (result *domain.AuthorDenormalized, err rest_errors.RestErr) {
+	defer r.observe("GetAuthorById", time.Now(), &err)
+	return r.next.GetAuthorById(ctx, id)
+}
+
+func (r *repository) ListAuthors(ctx context.Context, opts domain.ListOptions) (result []domain.Author, err rest_errors.RestErr) {
+	defer r.observe("ListAuthors", time.Now(), &err)
+	return r.next.ListAuthors(ctx, opts)
+}
+
+func (r *repository) GetAuthorByName(ctx context.Context, firstName string, lastName string) (result *domain.Author, err rest_errors.RestErr) {
+	defer r.observe("GetAuthorByName", time.Now(), &err)
+	return r.next.GetAuthorByName(ctx, firstName, lastName)
+}
+
+func (r *repository) SavePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) (err rest_errors.RestErr) {
+	defer r.observe("SavePublisher", time.Now(), &err)
+	return r.next.SavePublisher(ctx, actorID, publisher)
+}
+
+func (r *repository) UpdatePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) (err rest_errors.RestErr) {
+	defer r.observe("UpdatePublisher", time.Now(), &err)
+	return r.next.UpdatePublisher(ctx, actorID, publisher)
+}
+
+func (r *repository) GetPublisherById(ctx context.Context, id int64) (result *domain.PublisherDenormalized, err rest_errors.RestErr) {
+	defer r.observe("GetPublisherById", time.Now(), &err)
+	return r.next.GetPublisherById(ctx, id)
+}
+
+func (r *repository) ListPublishers(ctx context.Context, opts domain.ListOptions) (result []domain.Publisher, err rest_errors.RestErr) {
+	defer r.observe("ListPublishers", time.Now(), &err)
+	return r.next.ListPublishers(ctx, opts)
+}
+
+func (r *repository) GetPublisherByName(ctx context.Context, name string) (result *domain.Publisher, err rest_errors.RestErr) {
+	defer r.observe("GetPublisherByName", time.Now(), &err)
+	return r.next.GetPublisherByName(ctx, name)
+}
+
+func (r *repository) SaveBook(ctx context.Context, actorID int64, book *domain.Book) (err rest_errors.RestErr) {
+	defer r.observe("SaveBook", time.Now(), &err)
+	return r.next.SaveBook(ctx, actorID, book)
+}
+
+func (r *repository) SaveBooks(ctx context.Context, actorID int64, books []domain.Book) (err rest_errors.RestErr) {
+	defer r.observe("SaveBooks", time.Now(), &err)
+	return r.next.SaveBooks(ctx, actorID, books)
+}
+
+func (r *repository) SaveImportBatch(ctx context.Context, actorID int64, books []domain.Book, checkpoint domain.ImportCheckpoint) (err rest_errors.RestErr) {
+	defer r.observe("SaveImportBatch", time.Now(), &err)
+	return r.next.SaveImportBatch(ctx, actorID, books, checkpoint)
+}
+
+func (r *repository) GetImportCheckpoint(ctx context.Context, name string) (result int, err rest_errors.RestErr) {
+	defer r.observe("GetImportCheckpoint", time.Now(), &err)
+	return r.next.GetImportCheckpoint(ctx, name)
+}
+
+func (r *repository) UpdateBook(ctx context.Context, actorID int64, book *domain.Book) (err rest_errors.RestErr) {
+	defer r.observe("UpdateBook", time.Now(), &err)
+	return r.next.UpdateBook(ctx, actorID, book)
+}
+
+func (r *repository) GetBookById(ctx context.Context, id int64) (result *domain.BookDenormalized, err rest_errors.RestErr) {
+	defer r.observe("GetBookById", time.Now(), &err)
+	return r.next.GetBookById(ctx, id)
+}
+
+func (r *repository) GetBookByISBN(ctx context.Context, isbn string) (result *domain.Book, err rest_errors.RestErr) {
+	defer r.observe("GetBookByISBN", time.Now(), &err)
+	return r.next.GetBookByISBN(ctx, isbn)
+}
+
+func (r *repository) ListBooks(ctx context.Context, opts domain.ListOptions) (result []domain.Book, err rest_errors.RestErr) {
+	defer r.observe("ListBooks", time.Now(), &err)
+	return r.next.ListBooks(ctx, opts)
+}
+
+func (r *repository) ListSellerBooks(ctx context.Context, filter domain.SellerBooksFilter, opts domain.ListOptions) (result []domain.Book, err rest_errors.RestErr) {
+	defer r.observe("ListSellerBooks", time.Now(), &err)
+	return r.next.ListSellerBooks(ctx, filter, opts)
+}
+
+func (r *repository) UpdateBookStatus(ctx context.Context, actorID int64, bookID int64, version int64, from string, to string) (err rest_errors.RestErr) {
+	defer r.observe("UpdateBookStatus", time.Now(), &err)
+	return r.next.UpdateBookStatus(ctx, actorID, bookID, version, from, to)
+}
+
+func (r *repository) GetPublishersByIds(ctx context.Context, ids []int64) (result map[int64]domain.Publisher, err rest_errors.RestErr) {
+	defer r.observe("GetPublishersByIds", time.Now(), &err)
+	return r.next.GetPublishersByIds(ctx, ids)
+}
+
+func (r *repository) GetAuthorsByBookIds(ctx context.Context, ids []int64) (result map[int64][]domain.Author, err rest_errors.RestErr) {
+	defer r.observe("GetAuthorsByBookIds", time.Now(), &err)
+	return r.next.GetAuthorsByBookIds(ctx, ids)
+}
+
+func (r *repository) GetAuthorsByPublisherIds(ctx context.Context, ids []int64) (result map[int64][]domain.Author, err rest_errors.RestErr) {
+	defer r.observe("GetAuthorsByPublisherIds", time.Now(), &err)
+	return r.next.GetAuthorsByPublisherIds(ctx, ids)
+}
+
+func (r *repository) GetBooksByAuthorIds(ctx context.Context, ids []int64) (result map[int64][]domain.Book, err rest_errors.RestErr) {
+	defer r.observe("GetBooksByAuthorIds", time.Now(), &err)
+	return r.next.GetBooksByAuthorIds(ctx, ids)
+}
+
+func (r *repository) GetBooksByPublisherIds(ctx context.Context, ids []int64) (result map[int64][]domain.Book, err rest_errors.RestErr) {
+	defer r.observe("GetBooksByPublisherIds", time.Now(), &err)
+	return r.next.GetBooksByPublisherIds(ctx, ids)
+}
+
+func (r *repository) GetPendingBooks(ctx context.Context) (result []domain.Submission, err rest_errors.RestErr) {
+	defer r.observe("GetPendingBooks", time.Now(), &err)
+	return r.next.GetPendingBooks(ctx)
+}
+
+func (r *repository) ModerateBook(ctx context.Context, moderation *domain.Moderation) (err rest_errors.RestErr) {
+	defer r.observe("ModerateBook", time.Now(), &err)
+	return r.next.ModerateBook(ctx, moderation)
+}
+
+func (r *repository) GetModerations(ctx context.Context, id int64) (result []domain.Moderation, err rest_errors.RestErr) {
+	defer r.observe("GetModerations", time.Now(), &err)
+	return r.next.GetModerations(ctx, id)
+}
+
+func (r *repository) UpdateBookPrice(ctx context.Context, actorID int64, bookID int64, version int64, price int64) (err rest_errors.RestErr) {
+	defer r.observe("UpdateBookPrice", time.Now(), &err)
+	return r.next.UpdateBookPrice(ctx, actorID, bookID, version, price)
+}
+
+func (r *repository) GetPriceHistory(ctx context.Context, id int64) (result []domain.PriceChange, err rest_errors.RestErr) {
+	defer r.observe("GetPriceHistory", time.Now(), &err)
+	return r.next.GetPriceHistory(ctx, id)
+}
+
+func (r *repository) SavePromotion(ctx context.Context, actorID int64, promotion *domain.Promotion) (err rest_errors.RestErr) {
+	defer r.observe("SavePromotion", time.Now(), &err)
+	return r.next.SavePromotion(ctx, actorID, promotion)
+}
+
+func (r *repository) GetAuditLog(ctx context.Context, filter domain.AuditFilter) (result []domain.AuditEntry, err rest_errors.RestErr) {
+	defer r.observe("GetAuditLog", time.Now(), &err)
+	return r.next.GetAuditLog(ctx, filter)
+}
+
+func (r *repository) ExportBooks(ctx context.Context, fn func(domain.CatalogEntry) error) (err rest_errors.RestErr) {
+	defer r.observe("ExportBooks", time.Now(), &err)
+	return r.next.ExportBooks(ctx, fn)
+}
@@ -0,0 +1,143 @@
+// Package migrations applies the schema migrations embedded in the binary.
+// Versions are tracked in the same schema_migrations table the migrate CLI
+// uses, so databases it migrated carry on from where they are.
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration is a pair of NNNNNN_name.up.sql and NNNNNN_name.down.sql files,
+// the down one being optional
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations at the root of fsys, sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: must be named like 000001_name.up.sql", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: version must be a number from 1", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %s: version %d is taken by %s", entry.Name(), version, m)
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s: the up file is missing", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// split breaks a script into its statements, at the semicolons outside of
+// quotes and comments. Comments are left out, except for the /*! ones MySQL
+// runs.
+func split(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		rest := script[i:]
+		switch {
+		case rest[0] == '\'' || rest[0] == '"' || rest[0] == '`':
+			// a doubled quote is read as two strings in a row, which leaves
+			// the statement as it was all the same
+			end := 1
+			for end < len(rest) && rest[end] != rest[0] {
+				if rest[end] == '\\' && rest[0] != '`' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rest) {
+				end = len(rest) - 1
+			}
+			current.WriteString(rest[:end+1])
+			i += end
+		case rest[0] == '#' || isDashComment(rest):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			i += end - 1
+		case strings.HasPrefix(rest, "/*") && !strings.HasPrefix(rest, "/*!"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			current.WriteByte(' ')
+			i += end - 1
+		case rest[0] == ';':
+			flush()
+		default:
+			current.WriteByte(rest[0])
+		}
+	}
+	flush()
+	return statements
+}
+
+// isDashComment tells whether s starts with a -- comment, which MySQL only
+// takes as one when followed by a space or a control character
+func isDashComment(s string) bool {
+	return strings.HasPrefix(s, "--") && (len(s) == 2 || s[2] <= ' ')
+}
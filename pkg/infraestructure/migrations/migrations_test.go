@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/FacuBar/bookstore_books-api/migration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Embedded", func(t *testing.T) {
+		migrations, err := Load(migration.Files)
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, migrations)
+		for i, m := range migrations {
+			assert.EqualValues(t, i+1, m.Version)
+			assert.NotEmpty(t, split(m.Up), m.String())
+			assert.NotEmpty(t, split(m.Down), m.String())
+		}
+	})
+
+	t.Run("Sorted", func(t *testing.T) {
+		migrations, err := Load(fstest.MapFS{
+			"000010_later.up.sql":   {Data: []byte("SELECT 10;")},
+			"000002_first.up.sql":   {Data: []byte("SELECT 2;")},
+			"000002_first.down.sql": {Data: []byte("SELECT -2;")},
+			"README.md":             {Data: []byte("not a migration")},
+		})
+
+		assert.Nil(t, err)
+		assert.EqualValues(t, []Migration{
+			{Version: 2, Name: "first", Up: "SELECT 2;", Down: "SELECT -2;"},
+			{Version: 10, Name: "later", Up: "SELECT 10;"},
+		}, migrations)
+	})
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"BadName":      {"init.up.sql": {Data: []byte("SELECT 1;")}},
+		"VersionTaken": {"000001_a.up.sql": {Data: []byte("SELECT 1;")}, "000001_b.up.sql": {Data: []byte("SELECT 1;")}},
+		"MissingUp":    {"000001_a.down.sql": {Data: []byte("SELECT 1;")}},
+	} {
+		fsys := fsys
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fsys)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	script := "-- books get a status\n" +
+		"ALTER TABLE `books` ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'draft;pending';\n" +
+		"/* a comment; with a semicolon */\n" +
+		"UPDATE books SET note = \"it's; fine\" WHERE id = 1; # trailing\n" +
+		"/*!40101 SET NAMES utf8 */;\n" +
+		"  ;\n" +
+		"SELECT 1--1"
+
+	assert.EqualValues(t, []string{
+		"ALTER TABLE `books` ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'draft;pending'",
+		"UPDATE books SET note = \"it's; fine\" WHERE id = 1",
+		"/*!40101 SET NAMES utf8 */",
+		"SELECT 1--1",
+	}, split(script))
+}
@@ -0,0 +1,262 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	queryCreateTable = `-- create schema migrations
+CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL);`
+
+	queryGetVersion = `-- get schema version
+SELECT version, dirty FROM schema_migrations LIMIT 1;`
+
+	queryClearVersion = `-- clear schema version
+DELETE FROM schema_migrations;`
+
+	querySetVersion = `-- set schema version
+INSERT INTO schema_migrations (version, dirty) VALUES (?, ?);`
+
+	// the lock is named after the database, runners migrating other databases
+	// on the same server don't wait for each other
+	queryGetLock = `-- get migration lock
+SELECT GET_LOCK(CONCAT('schema_migrations.', DATABASE()), ?);`
+
+	queryReleaseLock = `-- release migration lock
+SELECT RELEASE_LOCK(CONCAT('schema_migrations.', DATABASE()));`
+)
+
+// ErrLocked is returned when another run held the lock for longer than the
+// lock timeout
+var ErrLocked = errors.New("another migration run holds the lock")
+
+// DirtyError is returned when a migration failed halfway through. The schema
+// has to be fixed by hand and its version forced before migrating again.
+type DirtyError struct {
+	Version uint64
+}
+
+func (e DirtyError) Error() string {
+	return fmt.Sprintf("version %d is dirty, a migration failed halfway through; fix the schema and force the version it's at", e.Version)
+}
+
+// Status is where the schema stands
+type Status struct {
+	// Version is the last migration applied, 0 when none is
+	Version uint64
+	Dirty   bool
+	// Pending are the migrations Up would apply
+	Pending []Migration
+}
+
+// Migrator applies migrations to a database. Runs hold a lock on it, so
+// runners started together, as replicas migrating on start, take turns and
+// the ones coming last find nothing left to do.
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+func New(db *sql.DB, migrations []Migration, lockTimeout time.Duration) *Migrator {
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Up applies the pending migrations, returning the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return DirtyError{Version: current}
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+			if err := run(ctx, conn, migration.Version, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("applying %s: %w", migration, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps migrations applied, returning the ones it
+// reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return DirtyError{Version: current}
+		}
+		if current == 0 {
+			return nil
+		}
+
+		i := m.index(current)
+		if i < 0 {
+			return fmt.Errorf("version %d isn't one of the migrations known, it was applied by a newer release", current)
+		}
+		for ; steps > 0 && i >= 0; steps, i = steps-1, i-1 {
+			migration := m.migrations[i]
+			if migration.Down == "" {
+				return fmt.Errorf("reverting %s: it has no down file", migration)
+			}
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := run(ctx, conn, migration.Version, migration.Down, previous); err != nil {
+				return fmt.Errorf("reverting %s: %w", migration, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status tells which version the schema is at. It doesn't wait for runs in
+// progress.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, queryCreateTable); err != nil {
+		return Status{}, err
+	}
+	current, dirty, err := version(ctx, conn)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Version: current, Dirty: dirty}
+	for _, migration := range m.migrations {
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Force records the schema as being at version, and clean, without running
+// anything. It's how a dirty schema, fixed by hand, is migrated again.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("version %d isn't one of the migrations known", version)
+	}
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, version, false)
+	})
+}
+
+// locked runs fn on a connection holding the migration lock, making sure
+// schema_migrations exists first. The lock is tied to the connection, which
+// is why every statement of a run goes through it.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, queryGetLock, int(m.lockTimeout.Seconds())).Scan(&acquired); err != nil {
+		return err
+	}
+	if acquired.Int64 != 1 {
+		return ErrLocked
+	}
+	defer func() {
+		// the lock goes away with the connection, which is discarded rather
+		// than put back in the pool if releasing it fails
+		var released sql.NullInt64
+		if err := conn.QueryRowContext(context.Background(), queryReleaseLock).Scan(&released); err != nil {
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, queryCreateTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) index(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// run runs script with the schema marked dirty at version, and records it at
+// target once every statement went through. MySQL commits schema changes as
+// they're made, a failed script is left dirty for someone to look into.
+func run(ctx context.Context, conn *sql.Conn, version uint64, script string, target uint64) error {
+	if err := setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	for _, statement := range split(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return setVersion(ctx, conn, target, false)
+}
+
+func version(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var (
+		current uint64
+		dirty   bool
+	)
+	err := conn.QueryRowContext(ctx, queryGetVersion).Scan(&current, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return current, dirty, err
+}
+
+// setVersion replaces the single row of schema_migrations, version 0 leaving
+// it empty as the migrate CLI does
+func setVersion(ctx context.Context, conn *sql.Conn, version uint64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, queryClearVersion); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, querySetVersion, version, dirty); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "init", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+	{Version: 2, Name: "b", Up: "CREATE TABLE b (id INT);\nCREATE TABLE c (id INT);", Down: "DROP TABLE c;\nDROP TABLE b;"},
+}
+
+func newMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return New(db, testMigrations, 5*time.Second), mock
+}
+
+func expectLock(mock sqlmock.Sqlmock, acquired int) {
+	mock.ExpectQuery(regexp.QuoteMeta(queryGetLock)).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(acquired))
+	if acquired == 1 {
+		mock.ExpectExec(regexp.QuoteMeta(queryCreateTable)).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version uint64, dirty bool) {
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if version > 0 {
+		rows.AddRow(version, dirty)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(queryGetVersion)).WillReturnRows(rows)
+}
+
+func expectSetVersion(mock sqlmock.Sqlmock, version uint64, dirty bool) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(queryClearVersion)).WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 {
+		mock.ExpectExec(regexp.QuoteMeta(querySetVersion)).WithArgs(version, dirty).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func expectRelease(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta(queryReleaseLock)).WillReturnRows(sqlmock.NewRows([]string{"released"}).AddRow(1))
+}
+
+func TestUp(t *testing.T) {
+	t.Run("NoError", func(t *testing.T) {
+		migrator, mock := newMigrator(t)
+		expectLock(mock, 1)
+		expectVersion(mock, 1, false)
+		expectSetVersion(mock, 2, true)
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE b (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE c (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		expectSetVersion(mock, 2, false)
+		expectRelease(mock)
+
+		applied, err := migrator.Up(context.Background())
+
+		assert.Nil(t, err)
+		assert.EqualValues(t, testMigrations[1:], applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		migrator, mock := newMigrator(t)
+		expectLock(mock, 1)
+		expectVersion(mock, 0, false)
+		expectSetVersion(mock, 1, true)
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a (id INT)")).WillReturnError(assert.AnError)
+		expectRelease(mock)
+
+		applied, err := migrator.Up(context.Background())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Dirty", func(t *testing.T) {
+		migrator, mock := newMigrator(t)
+		expectLock(mock, 1)
+		expectVersion(mock, 1, true)
+		expectRelease(mock)
+
+		_, err := migrator.Up(context.Background())
+
+		assert.EqualValues(t, DirtyError{Version: 1}, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		migrator, mock := newMigrator(t)
+		expectLock(mock, 0)
+
+		_, err := migrator.Up(context.Background())
+
+		assert.ErrorIs(t, err, ErrLocked)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDown(t *testing.T) {
+	migrator, mock := newMigrator(t)
+	expectLock(mock, 1)
+	expectVersion(mock, 2, false)
+	expectSetVersion(mock, 2, true)
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE c")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE b")).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectSetVersion(mock, 1, false)
+	expectSetVersion(mock, 1, true)
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE a")).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectSetVersion(mock, 0, false)
+	expectRelease(mock)
+
+	reverted, err := migrator.Down(context.Background(), 5)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []Migration{testMigrations[1], testMigrations[0]}, reverted)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus(t *testing.T) {
+	migrator, mock := newMigrator(t)
+	mock.ExpectExec(regexp.QuoteMeta(queryCreateTable)).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectVersion(mock, 1, false)
+
+	status, err := migrator.Status(context.Background())
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, Status{Version: 1, Pending: testMigrations[1:]}, status)
+}
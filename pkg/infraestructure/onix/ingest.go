@@ -0,0 +1,225 @@
+package onix
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionSkipped = "skipped"
+
+	DefaultCurrency = "USD"
+)
+
+// Ingester upserts the products of ONIX messages by ISBN.
+//
+// Authors missing from the catalog are created when the message carries their
+// birth date, publishers never are, ONIX doesn't describe them enough, so
+// their products are skipped until they're created.
+type Ingester struct {
+	Repo ports.BooksRepositoryInterface
+	// ActorID is recorded as the seller of new books and in the audit log
+	ActorID int64
+	// Currency is the one prices are read in, DefaultCurrency if empty
+	Currency string
+	// DryRun maps and resolves every product without writing anything
+	DryRun bool
+
+	publishers map[string]int64
+	authors    map[string]int64
+}
+
+// Result is the outcome of a product
+type Result struct {
+	Reference string   `json:"reference,omitempty"`
+	ISBN      string   `json:"isbn,omitempty"`
+	Action    string   `json:"action"`
+	BookID    int64    `json:"book_id,omitempty"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+type Report struct {
+	DryRun   bool           `json:"dry_run"`
+	Products int            `json:"products"`
+	Created  int            `json:"created"`
+	Updated  int            `json:"updated"`
+	Skipped  int            `json:"skipped"`
+	Results  []Result       `json:"results"`
+	Unmapped map[string]int `json:"unmapped_fields"`
+}
+
+// Ingest processes every product of the message, a product failing doesn't
+// stop the ones after it. It only returns an error if the message itself
+// can't be read, along with the report of what was ingested until then.
+func (in *Ingester) Ingest(ctx context.Context, r io.Reader) (*Report, error) {
+	currency := in.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	in.publishers = map[string]int64{}
+	in.authors = map[string]int64{}
+
+	parser := NewParser(r, currency)
+	report := &Report{DryRun: in.DryRun, Results: []Result{}, Unmapped: parser.Unmapped}
+
+	for {
+		item, err := parser.Next()
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		result := in.upsert(ctx, item)
+		report.Products++
+		switch result.Action {
+		case ActionCreated:
+			report.Created++
+		case ActionUpdated:
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+		report.Results = append(report.Results, result)
+	}
+}
+
+func (in *Ingester) upsert(ctx context.Context, item *Item) Result {
+	result := Result{Reference: item.Reference, ISBN: item.Book.ISBN, Action: ActionSkipped, Problems: item.Problems}
+	if len(result.Problems) > 0 {
+		return result
+	}
+	skip := func(format string, args ...interface{}) Result {
+		result.Problems = append(result.Problems, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	publisherID, err := in.resolvePublisher(ctx, item.Publisher.Name)
+	if err != nil {
+		return skip("%s", err.Message())
+	}
+
+	authorIDs := make([]int64, 0, len(item.Authors))
+	for i := range item.Authors {
+		authorID, err := in.resolveAuthor(ctx, &item.Authors[i])
+		if err != nil {
+			return skip("%s", err.Message())
+		}
+		authorIDs = append(authorIDs, authorID)
+	}
+
+	existing, err := in.Repo.GetBookByISBN(ctx, item.Book.ISBN)
+	if err != nil && err.Status() != http.StatusNotFound {
+		return skip("looking up the book: %s", err.Message())
+	}
+
+	book := item.Book
+	book.PublisherID = publisherID
+	book.AuthorID = authorIDs
+
+	if existing == nil {
+		book.SellerID = in.ActorID
+		book.Status = domain.StatusPendingReview
+		if !in.DryRun {
+			if err := in.Repo.SaveBook(ctx, in.ActorID, &book); err != nil {
+				return skip("creating the book: %s", err.Message())
+			}
+		}
+		result.Action = ActionCreated
+		result.BookID = book.ID
+		return result
+	}
+
+	result.BookID = existing.ID
+	merged := merge(*existing, book)
+	if !in.DryRun {
+		if err := in.Repo.UpdateBook(ctx, in.ActorID, &merged); err != nil {
+			return skip("updating the book: %s", err.Message())
+		}
+		if item.HasPrice && book.Price != existing.Price {
+			if err := in.Repo.UpdateBookPrice(ctx, in.ActorID, existing.ID, merged.Version, book.Price); err != nil {
+				return skip("updating the price: %s", err.Message())
+			}
+		}
+	}
+	result.Action = ActionUpdated
+	return result
+}
+
+// merge lays the fields the product carries over the existing book, the ones
+// it leaves empty keep their current value
+func merge(existing domain.Book, product domain.Book) domain.Book {
+	merged := existing
+	merged.AuthorID = product.AuthorID
+	merged.PublisherID = product.PublisherID
+
+	for _, field := range []struct {
+		into  *string
+		value string
+	}{
+		{&merged.Title, product.Title},
+		{&merged.OriginalRelease, product.OriginalRelease},
+		{&merged.Description, product.Description},
+		{&merged.ShortDescription, product.ShortDescription},
+		{&merged.Published, product.Published},
+	} {
+		if field.value != "" {
+			*field.into = field.value
+		}
+	}
+	if product.Pages > 0 {
+		merged.Pages = product.Pages
+	}
+
+	return merged
+}
+
+func (in *Ingester) resolvePublisher(ctx context.Context, name string) (int64, rest_errors.RestErr) {
+	if id, ok := in.publishers[name]; ok {
+		return id, nil
+	}
+
+	publisher, err := in.Repo.GetPublisherByName(ctx, name)
+	if err != nil {
+		if err.Status() == http.StatusNotFound {
+			return 0, rest_errors.NewNotFoundError(fmt.Sprintf("publisher %q doesn't exist, create it before ingesting its titles", name))
+		}
+		return 0, err
+	}
+
+	in.publishers[name] = publisher.ID
+	return publisher.ID, nil
+}
+
+func (in *Ingester) resolveAuthor(ctx context.Context, author *domain.Author) (int64, rest_errors.RestErr) {
+	key := author.LastName + ", " + author.FirstName
+	if id, ok := in.authors[key]; ok {
+		return id, nil
+	}
+
+	existing, err := in.Repo.GetAuthorByName(ctx, author.FirstName, author.LastName)
+	switch {
+	case err == nil:
+		author.ID = existing.ID
+	case err.Status() != http.StatusNotFound:
+		return 0, err
+	case author.Birthday == "":
+		return 0, rest_errors.NewNotFoundError(fmt.Sprintf("author %q doesn't exist and the message lacks their birth date to create them", key))
+	case !in.DryRun:
+		if err := in.Repo.SaveAuthor(ctx, in.ActorID, author); err != nil {
+			return 0, err
+		}
+	}
+
+	in.authors[key] = author.ID
+	return author.ID, nil
+}
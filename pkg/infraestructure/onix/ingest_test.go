@@ -0,0 +1,142 @@
+package onix
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo implements the part of the repository the ingester uses, calling
+// anything else panics
+type fakeRepo struct {
+	ports.BooksRepositoryInterface
+
+	publishers map[string]int64
+	authors    map[string]int64
+	books      map[string]domain.Book
+	prices     map[int64]int64
+	nextID     int64
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		publishers: map[string]int64{"Grove Atlantic": 1},
+		authors:    map[string]int64{},
+		books:      map[string]domain.Book{},
+		prices:     map[int64]int64{},
+		nextID:     100,
+	}
+}
+
+func (f *fakeRepo) GetPublisherByName(ctx context.Context, name string) (*domain.Publisher, rest_errors.RestErr) {
+	if id, ok := f.publishers[name]; ok {
+		return &domain.Publisher{ID: id, Name: name}, nil
+	}
+	return nil, rest_errors.NewNotFoundError("publisher not found")
+}
+
+func (f *fakeRepo) GetAuthorByName(ctx context.Context, firstName string, lastName string) (*domain.Author, rest_errors.RestErr) {
+	if id, ok := f.authors[lastName+", "+firstName]; ok {
+		return &domain.Author{ID: id}, nil
+	}
+	return nil, rest_errors.NewNotFoundError("author not found")
+}
+
+func (f *fakeRepo) SaveAuthor(ctx context.Context, actorID int64, author *domain.Author) rest_errors.RestErr {
+	f.nextID++
+	author.ID = f.nextID
+	f.authors[author.LastName+", "+author.FirstName] = author.ID
+	return nil
+}
+
+func (f *fakeRepo) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, rest_errors.RestErr) {
+	if book, ok := f.books[isbn]; ok {
+		return &book, nil
+	}
+	return nil, rest_errors.NewNotFoundError("book not found")
+}
+
+func (f *fakeRepo) SaveBook(ctx context.Context, actorID int64, book *domain.Book) rest_errors.RestErr {
+	f.nextID++
+	book.ID = f.nextID
+	book.Version = 1
+	f.books[book.ISBN] = *book
+	return nil
+}
+
+func (f *fakeRepo) UpdateBook(ctx context.Context, actorID int64, book *domain.Book) rest_errors.RestErr {
+	book.Version++
+	f.books[book.ISBN] = *book
+	return nil
+}
+
+func (f *fakeRepo) UpdateBookPrice(ctx context.Context, actorID int64, bookID int64, version int64, price int64) rest_errors.RestErr {
+	f.prices[bookID] = price
+	return nil
+}
+
+func TestIngestCreatesThenUpdates(t *testing.T) {
+	repo := newFakeRepo()
+	ingester := Ingester{Repo: repo, ActorID: 7}
+
+	report, err := ingester.Ingest(context.Background(), strings.NewReader(testMessage))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, report.Products)
+	assert.EqualValues(t, 1, report.Created)
+	assert.EqualValues(t, 1, report.Skipped)
+	assert.EqualValues(t, ActionCreated, report.Results[0].Action)
+	assert.EqualValues(t, 1, report.Unmapped["DescriptiveDetail/ProductForm"])
+
+	created := repo.books["9780802130303"]
+	assert.EqualValues(t, domain.StatusPendingReview, created.Status)
+	assert.EqualValues(t, 7, created.SellerID)
+	assert.EqualValues(t, 1, created.PublisherID)
+	assert.EqualValues(t, []int64{repo.authors["Borges, Jorge Luis"]}, created.AuthorID)
+	assert.EqualValues(t, 1600, created.Price)
+
+	existing := created
+	existing.ShortDescription = "kept"
+	existing.Status = domain.StatusPublished
+	repo.books["9780802130303"] = existing
+
+	message := strings.Replace(testMessage, "<PriceAmount>16.00</PriceAmount>", "<PriceAmount>18.00</PriceAmount>", 1)
+	report, err = ingester.Ingest(context.Background(), strings.NewReader(message))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, report.Updated)
+	assert.EqualValues(t, created.ID, report.Results[0].BookID)
+
+	updated := repo.books["9780802130303"]
+	assert.EqualValues(t, "kept", updated.ShortDescription)
+	assert.EqualValues(t, domain.StatusPublished, updated.Status)
+	assert.EqualValues(t, 2, updated.Version)
+	assert.EqualValues(t, 1800, repo.prices[created.ID])
+}
+
+func TestIngestSkipsUnknownPublishers(t *testing.T) {
+	repo := newFakeRepo()
+	delete(repo.publishers, "Grove Atlantic")
+	ingester := Ingester{Repo: repo, ActorID: 7}
+
+	report, err := ingester.Ingest(context.Background(), strings.NewReader(testMessage))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, report.Skipped)
+	assert.Contains(t, report.Results[0].Problems[0], "Grove Atlantic")
+	assert.Empty(t, repo.books)
+}
+
+func TestIngestDryRun(t *testing.T) {
+	repo := newFakeRepo()
+	ingester := Ingester{Repo: repo, ActorID: 7, DryRun: true}
+
+	report, err := ingester.Ingest(context.Background(), strings.NewReader(testMessage))
+	assert.Nil(t, err)
+	assert.True(t, report.DryRun)
+	assert.EqualValues(t, 1, report.Created)
+	assert.Empty(t, repo.books)
+	assert.Empty(t, repo.authors)
+}
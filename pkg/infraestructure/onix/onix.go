@@ -0,0 +1,440 @@
+// Package onix reads ONIX 3.0 product feeds, as publishers send them, into
+// the catalog.
+package onix
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+)
+
+// Item is an ONIX Product mapped into the catalog, Problems listing why it
+// can't be ingested if anything prevents it
+type Item struct {
+	Reference string
+	Book      domain.Book
+	// HasPrice is set when the product is priced in the requested currency
+	HasPrice  bool
+	Publisher domain.Publisher
+	Authors   []domain.Author
+	Problems  []string
+}
+
+// Parser maps the Products of an ONIX 3.0 message one at a time, without
+// loading the message in memory
+type Parser struct {
+	d *xml.Decoder
+	// currency is the one prices are taken in, there's a single price per book
+	currency string
+	started  bool
+
+	// Unmapped counts the elements, by path relative to their Product, that
+	// were found in the message but have no place in the catalog
+	Unmapped map[string]int
+}
+
+func NewParser(r io.Reader, currency string) *Parser {
+	return &Parser{
+		d:        xml.NewDecoder(r),
+		currency: strings.ToUpper(currency),
+		Unmapped: map[string]int{},
+	}
+}
+
+// Next returns the following Product of the message, or io.EOF once there's
+// none left
+func (p *Parser) Next() (*Item, error) {
+	for {
+		token, err := p.d.Token()
+		if err == io.EOF && !p.started {
+			return nil, errors.New("the file has no ONIXMessage")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !p.started {
+			if err := checkMessage(start); err != nil {
+				return nil, err
+			}
+			p.started = true
+			continue
+		}
+
+		if start.Name.Local != "Product" {
+			if start.Name.Local != "Header" {
+				p.Unmapped[start.Name.Local]++
+			}
+			if err := p.d.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var raw product
+		if err := p.d.DecodeElement(&raw, &start); err != nil {
+			return nil, err
+		}
+		if err := p.countUnmapped(raw.Inner); err != nil {
+			return nil, err
+		}
+		return p.mapProduct(raw), nil
+	}
+}
+
+func checkMessage(root xml.StartElement) error {
+	switch root.Name.Local {
+	case "ONIXMessage":
+	case "ONIXmessage":
+		return errors.New("short tag ONIX messages aren't supported, send them with reference tags")
+	default:
+		return fmt.Errorf("expected an ONIXMessage, found %s", root.Name.Local)
+	}
+
+	for _, attr := range root.Attr {
+		if attr.Name.Local == "release" && !strings.HasPrefix(attr.Value, "3.") {
+			return fmt.Errorf("ONIX release %s isn't supported, only 3.0 is", attr.Value)
+		}
+	}
+	return nil
+}
+
+// countUnmapped walks the product looking for leaf elements out of
+// mappedPaths
+func (p *Parser) countUnmapped(inner []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(inner))
+
+	var path []string
+	var hasChildren []bool
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(hasChildren) > 0 {
+				hasChildren[len(hasChildren)-1] = true
+			}
+			path = append(path, t.Name.Local)
+			hasChildren = append(hasChildren, false)
+		case xml.EndElement:
+			leaf := !hasChildren[len(hasChildren)-1]
+			if joined := strings.Join(path, "/"); leaf && !mappedPaths[joined] {
+				p.Unmapped[joined]++
+			}
+			path = path[:len(path)-1]
+			hasChildren = hasChildren[:len(hasChildren)-1]
+		}
+	}
+}
+
+// ONIX code list values the mapping relies on
+const (
+	notificationDelete = "05"
+
+	idTypeISBN10 = "02"
+	idTypeGTIN13 = "03"
+	idTypeISBN13 = "15"
+
+	titleTypeDistinctive = "01"
+	titleLevelProduct    = "01"
+
+	roleAuthor = "A01"
+	dateBirth  = "50"
+
+	extentMainContent  = "00"
+	extentContentPages = "11"
+	extentUnitPages    = "03"
+
+	textShortDescription = "02"
+	textDescription      = "03"
+
+	publishingRolePublisher = "01"
+	dateRolePublication     = "01"
+	dateRoleFirstPublished  = "11"
+
+	priceRRPIncludingTax = "02"
+	priceRRPExcludingTax = "01"
+)
+
+func (p *Parser) mapProduct(raw product) *Item {
+	item := &Item{Reference: strings.TrimSpace(raw.RecordReference)}
+	problem := func(format string, args ...interface{}) {
+		item.Problems = append(item.Problems, fmt.Sprintf(format, args...))
+	}
+
+	if raw.NotificationType == notificationDelete {
+		problem("deletion notices aren't supported, withdraw the book instead")
+	}
+
+	isbn, err := productISBN(raw.ProductIdentifiers)
+	if err != nil {
+		problem("%s", err)
+	}
+	item.Book.ISBN = isbn
+
+	item.Book.Title = productTitle(raw.DescriptiveDetail.TitleDetails)
+	if item.Book.Title == "" {
+		problem("no distinctive title")
+	}
+
+	contributors := raw.DescriptiveDetail.Contributors
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return contributors[i].SequenceNumber < contributors[j].SequenceNumber
+	})
+	for _, c := range contributors {
+		if !hasRole(c, roleAuthor) {
+			continue
+		}
+		author := domain.Author{
+			FirstName: strings.TrimSpace(c.NamesBeforeKey),
+			LastName:  strings.TrimSpace(c.KeyNames),
+			Biography: strings.TrimSpace(c.BiographicalNote),
+		}
+		if author.LastName == "" {
+			name := strings.TrimSpace(c.PersonName)
+			if space := strings.LastIndex(name, " "); space >= 0 {
+				author.FirstName, author.LastName = name[:space], name[space+1:]
+			} else {
+				author.LastName = name
+			}
+		}
+		for _, d := range c.ContributorDates {
+			if d.ContributorDateRole != dateBirth {
+				continue
+			}
+			if author.Birthday, err = d.Date.parse(); err != nil {
+				problem("birth date of %s %s: %s", author.FirstName, author.LastName, err)
+			}
+		}
+		item.Authors = append(item.Authors, author)
+	}
+	if len(item.Authors) == 0 {
+		problem("no author among the contributors")
+	}
+
+	item.Book.Pages = productPages(raw.DescriptiveDetail.Extents)
+
+	for _, text := range raw.CollateralDetail.TextContents {
+		content := strings.TrimSpace(strings.Join(text.Texts, "\n"))
+		switch text.TextType {
+		case textDescription:
+			if item.Book.Description == "" {
+				item.Book.Description = content
+			}
+		case textShortDescription:
+			if item.Book.ShortDescription == "" {
+				item.Book.ShortDescription = content
+			}
+		}
+	}
+
+	item.Publisher.Name = productPublisher(raw.PublishingDetail)
+	if item.Publisher.Name == "" {
+		problem("no publisher nor imprint")
+	}
+
+	for _, d := range raw.PublishingDetail.PublishingDates {
+		parsed, err := d.Date.parse()
+		switch d.PublishingDateRole {
+		case dateRolePublication:
+			item.Book.Published = parsed
+		case dateRoleFirstPublished:
+			item.Book.OriginalRelease = parsed
+		default:
+			continue
+		}
+		if err != nil {
+			problem("publishing date: %s", err)
+		}
+	}
+	if item.Book.Published == "" {
+		problem("no publication date")
+	}
+	if item.Book.OriginalRelease == "" {
+		item.Book.OriginalRelease = item.Book.Published
+	}
+
+	if amount, ok := p.productPrice(raw.ProductSupply); ok {
+		cents, err := parseCents(amount)
+		if err != nil {
+			problem("price %q: %s", amount, err)
+		}
+		item.Book.Price = cents
+		item.HasPrice = err == nil
+	}
+
+	return item
+}
+
+func productISBN(ids []productIdentifier) (string, error) {
+	byType := map[string]string{}
+	for _, id := range ids {
+		byType[id.ProductIDType] = strings.TrimSpace(id.IDValue)
+	}
+
+	if isbn, ok := byType[idTypeISBN13]; ok {
+		return domain.NormalizeISBN(isbn)
+	}
+	if gtin, ok := byType[idTypeGTIN13]; ok && (strings.HasPrefix(gtin, "978") || strings.HasPrefix(gtin, "979")) {
+		return domain.NormalizeISBN(gtin)
+	}
+	if isbn, ok := byType[idTypeISBN10]; ok {
+		return domain.NormalizeISBN(isbn)
+	}
+	return "", errors.New("no ISBN among the product identifiers")
+}
+
+func productTitle(details []titleDetail) string {
+	for _, detail := range details {
+		if detail.TitleType != titleTypeDistinctive {
+			continue
+		}
+		for _, element := range detail.TitleElements {
+			if element.TitleElementLevel != titleLevelProduct {
+				continue
+			}
+			title := strings.TrimSpace(element.TitleText)
+			if title == "" {
+				title = strings.TrimSpace(element.TitlePrefix + " " + element.TitleWithoutPrefix)
+			}
+			if subtitle := strings.TrimSpace(element.Subtitle); subtitle != "" && title != "" {
+				title += ": " + subtitle
+			}
+			return title
+		}
+	}
+	return ""
+}
+
+func hasRole(c contributor, role string) bool {
+	for _, r := range c.ContributorRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func productPages(extents []extent) int64 {
+	for _, extentType := range []string{extentMainContent, extentContentPages} {
+		for _, e := range extents {
+			if e.ExtentType != extentType || e.ExtentUnit != extentUnitPages {
+				continue
+			}
+			if pages, err := strconv.ParseInt(strings.TrimSpace(e.ExtentValue), 10, 64); err == nil {
+				return pages
+			}
+		}
+	}
+	return 0
+}
+
+func productPublisher(detail publishingDetail) string {
+	for _, p := range detail.Publishers {
+		if p.PublishingRole == publishingRolePublisher && strings.TrimSpace(p.PublisherName) != "" {
+			return strings.TrimSpace(p.PublisherName)
+		}
+	}
+	for _, i := range detail.Imprints {
+		if name := strings.TrimSpace(i.ImprintName); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// productPrice returns the recommended retail price in the parser's currency,
+// the tax inclusive one if there are both
+func (p *Parser) productPrice(supplies []productSupply) (string, bool) {
+	found := map[string]string{}
+	for _, supply := range supplies {
+		for _, detail := range supply.SupplyDetails {
+			for _, price := range detail.Prices {
+				if strings.ToUpper(strings.TrimSpace(price.CurrencyCode)) != p.currency {
+					continue
+				}
+				if _, ok := found[price.PriceType]; !ok {
+					found[price.PriceType] = strings.TrimSpace(price.PriceAmount)
+				}
+			}
+		}
+	}
+
+	for _, priceType := range []string{priceRRPIncludingTax, priceRRPExcludingTax} {
+		if amount, ok := found[priceType]; ok {
+			return amount, true
+		}
+	}
+	return "", false
+}
+
+// parseCents turns a decimal amount such as 19.9 into cents
+func parseCents(amount string) (int64, error) {
+	units, fraction := amount, ""
+	if dot := strings.Index(amount, "."); dot >= 0 {
+		units, fraction = amount[:dot], amount[dot+1:]
+	}
+	if len(fraction) > 2 {
+		return 0, errors.New("prices can't have fractions of cents")
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	cents, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil || cents < 0 {
+		return 0, errors.New("not a positive amount")
+	}
+	return cents, nil
+}
+
+// parse returns the date in the YYYY-MM-DD layout of DATE columns, dates
+// coarser than a day are taken as their first day
+func (d date) parse() (string, error) {
+	value := strings.TrimSpace(d.Value)
+
+	switch d.Format {
+	case "", "00":
+		if len(value) != 8 {
+			return "", fmt.Errorf("%q isn't a YYYYMMDD date", value)
+		}
+	case "01":
+		if len(value) != 6 {
+			return "", fmt.Errorf("%q isn't a YYYYMM date", value)
+		}
+		value += "01"
+	case "05":
+		if len(value) != 4 {
+			return "", fmt.Errorf("%q isn't a YYYY date", value)
+		}
+		value += "0101"
+	case "13", "14":
+		if len(value) < 8 {
+			return "", fmt.Errorf("%q isn't a YYYYMMDDThhmm date", value)
+		}
+		value = value[:8]
+	default:
+		return "", fmt.Errorf("dateformat %s isn't supported", d.Format)
+	}
+
+	if _, err := strconv.Atoi(value); err != nil {
+		return "", fmt.Errorf("%q isn't a date", d.Value)
+	}
+	return value[:4] + "-" + value[4:6] + "-" + value[6:], nil
+}
@@ -0,0 +1,168 @@
+package onix
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMessage = `<?xml version="1.0" encoding="UTF-8"?>
+<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">
+  <Header>
+    <Sender><SenderName>Grove Atlantic</SenderName></Sender>
+    <SentDateTime>20211220T1030</SentDateTime>
+  </Header>
+  <Product>
+    <RecordReference>grove.9780802130303</RecordReference>
+    <NotificationType>03</NotificationType>
+    <ProductIdentifier>
+      <ProductIDType>01</ProductIDType>
+      <IDValue>GRV-0303</IDValue>
+    </ProductIdentifier>
+    <ProductIdentifier>
+      <ProductIDType>15</ProductIDType>
+      <IDValue>978-0-8021-3030-3</IDValue>
+    </ProductIdentifier>
+    <DescriptiveDetail>
+      <ProductComposition>00</ProductComposition>
+      <ProductForm>BC</ProductForm>
+      <TitleDetail>
+        <TitleType>01</TitleType>
+        <TitleElement>
+          <TitleElementLevel>01</TitleElementLevel>
+          <TitleText>Ficciones</TitleText>
+        </TitleElement>
+      </TitleDetail>
+      <Contributor>
+        <SequenceNumber>2</SequenceNumber>
+        <ContributorRole>B06</ContributorRole>
+        <PersonName>Anthony Kerrigan</PersonName>
+      </Contributor>
+      <Contributor>
+        <SequenceNumber>1</SequenceNumber>
+        <ContributorRole>A01</ContributorRole>
+        <NamesBeforeKey>Jorge Luis</NamesBeforeKey>
+        <KeyNames>Borges</KeyNames>
+        <ContributorDate>
+          <ContributorDateRole>50</ContributorDateRole>
+          <Date>18990824</Date>
+        </ContributorDate>
+      </Contributor>
+      <Extent>
+        <ExtentType>00</ExtentType>
+        <ExtentValue>174</ExtentValue>
+        <ExtentUnit>03</ExtentUnit>
+      </Extent>
+    </DescriptiveDetail>
+    <CollateralDetail>
+      <TextContent>
+        <TextType>03</TextType>
+        <ContentAudience>00</ContentAudience>
+        <Text>Seventeen stories.</Text>
+      </TextContent>
+    </CollateralDetail>
+    <PublishingDetail>
+      <Imprint><ImprintName>Grove Press</ImprintName></Imprint>
+      <Publisher>
+        <PublishingRole>01</PublishingRole>
+        <PublisherName>Grove Atlantic</PublisherName>
+      </Publisher>
+      <PublishingDate>
+        <PublishingDateRole>01</PublishingDateRole>
+        <Date dateformat="00">19620115</Date>
+      </PublishingDate>
+      <PublishingDate>
+        <PublishingDateRole>11</PublishingDateRole>
+        <Date dateformat="05">1944</Date>
+      </PublishingDate>
+    </PublishingDetail>
+    <ProductSupply>
+      <SupplyDetail>
+        <Price>
+          <PriceType>02</PriceType>
+          <PriceAmount>11.5</PriceAmount>
+          <CurrencyCode>GBP</CurrencyCode>
+        </Price>
+        <Price>
+          <PriceType>01</PriceType>
+          <PriceAmount>16.00</PriceAmount>
+          <CurrencyCode>USD</CurrencyCode>
+        </Price>
+      </SupplyDetail>
+    </ProductSupply>
+  </Product>
+  <Product>
+    <RecordReference>grove.delete</RecordReference>
+    <NotificationType>05</NotificationType>
+    <ProductIdentifier>
+      <ProductIDType>15</ProductIDType>
+      <IDValue>9780802130304</IDValue>
+    </ProductIdentifier>
+  </Product>
+</ONIXMessage>
+`
+
+func TestParser(t *testing.T) {
+	parser := NewParser(strings.NewReader(testMessage), "usd")
+
+	item, err := parser.Next()
+	assert.Nil(t, err)
+	assert.Empty(t, item.Problems)
+	assert.EqualValues(t, "grove.9780802130303", item.Reference)
+	assert.EqualValues(t, "9780802130303", item.Book.ISBN)
+	assert.EqualValues(t, "Ficciones", item.Book.Title)
+	assert.EqualValues(t, "Seventeen stories.", item.Book.Description)
+	assert.EqualValues(t, 174, item.Book.Pages)
+	assert.EqualValues(t, "1962-01-15", item.Book.Published)
+	assert.EqualValues(t, "1944-01-01", item.Book.OriginalRelease)
+	assert.EqualValues(t, "Grove Atlantic", item.Publisher.Name)
+	assert.True(t, item.HasPrice)
+	assert.EqualValues(t, 1600, item.Book.Price)
+
+	assert.Len(t, item.Authors, 1)
+	assert.EqualValues(t, "Jorge Luis", item.Authors[0].FirstName)
+	assert.EqualValues(t, "Borges", item.Authors[0].LastName)
+	assert.EqualValues(t, "1899-08-24", item.Authors[0].Birthday)
+
+	item, err = parser.Next()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, item.Problems)
+	assert.Contains(t, item.Problems[0], "deletion")
+	assert.Contains(t, item.Problems[1], "ISBN-13")
+
+	_, err = parser.Next()
+	assert.Equal(t, io.EOF, err)
+
+	assert.EqualValues(t, 1, parser.Unmapped["DescriptiveDetail/ProductForm"])
+	assert.EqualValues(t, 1, parser.Unmapped["CollateralDetail/TextContent/ContentAudience"])
+	assert.NotContains(t, parser.Unmapped, "DescriptiveDetail/TitleDetail/TitleElement/TitleText")
+	assert.NotContains(t, parser.Unmapped, "Header")
+}
+
+func TestParserRejectsOtherMessages(t *testing.T) {
+	for name, message := range map[string]string{
+		"ShortTags": `<ONIXmessage release="3.0"></ONIXmessage>`,
+		"Release2":  `<ONIXMessage release="2.1"></ONIXMessage>`,
+		"NotONIX":   `<books></books>`,
+		"Empty":     ``,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewParser(strings.NewReader(message), DefaultCurrency).Next()
+			assert.NotNil(t, err)
+			assert.NotEqual(t, io.EOF, err)
+		})
+	}
+}
+
+func TestParseCents(t *testing.T) {
+	for amount, cents := range map[string]int64{"16": 1600, "11.5": 1150, "0.99": 99} {
+		parsed, err := parseCents(amount)
+		assert.Nil(t, err)
+		assert.EqualValues(t, cents, parsed)
+	}
+
+	_, err := parseCents("1.999")
+	assert.NotNil(t, err)
+}
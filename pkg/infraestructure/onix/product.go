@@ -0,0 +1,146 @@
+package onix
+
+// The types below mirror the parts of an ONIX 3.0 reference tag Product that
+// are mapped into the catalog, everything else is reported as unmapped
+
+type product struct {
+	RecordReference    string              `xml:"RecordReference"`
+	NotificationType   string              `xml:"NotificationType"`
+	ProductIdentifiers []productIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  descriptiveDetail   `xml:"DescriptiveDetail"`
+	CollateralDetail   collateralDetail    `xml:"CollateralDetail"`
+	PublishingDetail   publishingDetail    `xml:"PublishingDetail"`
+	ProductSupply      []productSupply     `xml:"ProductSupply"`
+	Inner              []byte              `xml:",innerxml"`
+}
+
+type productIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type descriptiveDetail struct {
+	TitleDetails []titleDetail `xml:"TitleDetail"`
+	Contributors []contributor `xml:"Contributor"`
+	Extents      []extent      `xml:"Extent"`
+}
+
+type titleDetail struct {
+	TitleType     string         `xml:"TitleType"`
+	TitleElements []titleElement `xml:"TitleElement"`
+}
+
+type titleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText"`
+	TitlePrefix        string `xml:"TitlePrefix"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix"`
+	Subtitle           string `xml:"Subtitle"`
+}
+
+type contributor struct {
+	SequenceNumber   int               `xml:"SequenceNumber"`
+	ContributorRoles []string          `xml:"ContributorRole"`
+	PersonName       string            `xml:"PersonName"`
+	NamesBeforeKey   string            `xml:"NamesBeforeKey"`
+	KeyNames         string            `xml:"KeyNames"`
+	BiographicalNote string            `xml:"BiographicalNote"`
+	ContributorDates []contributorDate `xml:"ContributorDate"`
+}
+
+type contributorDate struct {
+	ContributorDateRole string `xml:"ContributorDateRole"`
+	Date                date   `xml:"Date"`
+}
+
+type extent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue string `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type collateralDetail struct {
+	TextContents []textContent `xml:"TextContent"`
+}
+
+type textContent struct {
+	TextType string   `xml:"TextType"`
+	Texts    []string `xml:"Text"`
+}
+
+type publishingDetail struct {
+	Imprints        []imprint        `xml:"Imprint"`
+	Publishers      []publisher      `xml:"Publisher"`
+	PublishingDates []publishingDate `xml:"PublishingDate"`
+}
+
+type imprint struct {
+	ImprintName string `xml:"ImprintName"`
+}
+
+type publisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+type publishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               date   `xml:"Date"`
+}
+
+type productSupply struct {
+	SupplyDetails []supplyDetail `xml:"SupplyDetail"`
+}
+
+type supplyDetail struct {
+	Prices []price `xml:"Price"`
+}
+
+type price struct {
+	PriceType    string `xml:"PriceType"`
+	PriceAmount  string `xml:"PriceAmount"`
+	CurrencyCode string `xml:"CurrencyCode"`
+}
+
+// date is an ONIX date, its format defaults to YYYYMMDD and can be changed
+// through the dateformat attribute
+type date struct {
+	Format string `xml:"dateformat,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// mappedPaths are the elements of a Product, relative to it, whose content
+// ends up in the catalog or drives how it's mapped
+var mappedPaths = map[string]bool{
+	"RecordReference":                                                   true,
+	"NotificationType":                                                  true,
+	"ProductIdentifier/ProductIDType":                                   true,
+	"ProductIdentifier/IDValue":                                         true,
+	"DescriptiveDetail/TitleDetail/TitleType":                           true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitleElementLevel":      true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitleText":              true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitlePrefix":            true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitleWithoutPrefix":     true,
+	"DescriptiveDetail/TitleDetail/TitleElement/Subtitle":               true,
+	"DescriptiveDetail/Contributor/SequenceNumber":                      true,
+	"DescriptiveDetail/Contributor/ContributorRole":                     true,
+	"DescriptiveDetail/Contributor/PersonName":                          true,
+	"DescriptiveDetail/Contributor/NamesBeforeKey":                      true,
+	"DescriptiveDetail/Contributor/KeyNames":                            true,
+	"DescriptiveDetail/Contributor/BiographicalNote":                    true,
+	"DescriptiveDetail/Contributor/ContributorDate/ContributorDateRole": true,
+	"DescriptiveDetail/Contributor/ContributorDate/Date":                true,
+	"DescriptiveDetail/Extent/ExtentType":                               true,
+	"DescriptiveDetail/Extent/ExtentValue":                              true,
+	"DescriptiveDetail/Extent/ExtentUnit":                               true,
+	"CollateralDetail/TextContent/TextType":                             true,
+	"CollateralDetail/TextContent/Text":                                 true,
+	"PublishingDetail/Imprint/ImprintName":                              true,
+	"PublishingDetail/Publisher/PublishingRole":                         true,
+	"PublishingDetail/Publisher/PublisherName":                          true,
+	"PublishingDetail/PublishingDate/PublishingDateRole":                true,
+	"PublishingDetail/PublishingDate/Date":                              true,
+	"ProductSupply/SupplyDetail/Price/PriceType":                        true,
+	"ProductSupply/SupplyDetail/Price/PriceAmount":                      true,
+	"ProductSupply/SupplyDetail/Price/CurrencyCode":                     true,
+}
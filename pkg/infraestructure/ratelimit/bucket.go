@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+)
+
+// now is replaced by tests
+var now = time.Now
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// buckets keeps a token bucket per key, each holding up to rate.Requests
+// tokens and refilled with rate.Requests tokens every rate.Period
+type buckets struct {
+	rate      config.Rate
+	perSecond float64
+
+	mu        sync.Mutex
+	byKey     map[string]*bucket
+	lastSweep time.Time
+}
+
+func newBuckets(rate config.Rate) *buckets {
+	if rate.Requests <= 0 || rate.Period <= 0 {
+		return nil
+	}
+	return &buckets{
+		rate:      rate,
+		perSecond: float64(rate.Requests) / rate.Period.Seconds(),
+		byKey:     make(map[string]*bucket),
+		lastSweep: now(),
+	}
+}
+
+// quota is the state of a bucket after a request was counted against it
+type quota struct {
+	allowed   bool
+	remaining int
+	// retryAfter is how long until a token is available, reset how long until
+	// the bucket is full again
+	retryAfter time.Duration
+	reset      time.Duration
+}
+
+// take counts a request against key's bucket
+func (b *buckets) take(key string) quota {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := now()
+	b.sweep(t)
+
+	capacity := float64(b.rate.Requests)
+	bk, ok := b.byKey[key]
+	if !ok {
+		bk = &bucket{tokens: capacity, last: t}
+		b.byKey[key] = bk
+	}
+	bk.tokens = math.Min(capacity, bk.tokens+t.Sub(bk.last).Seconds()*b.perSecond)
+	bk.last = t
+
+	q := quota{allowed: bk.tokens >= 1}
+	if q.allowed {
+		bk.tokens--
+	}
+	if !q.allowed {
+		q.retryAfter = b.timeFor(1 - bk.tokens)
+	}
+	q.remaining = int(bk.tokens)
+	q.reset = b.timeFor(capacity - bk.tokens)
+	return q
+}
+
+// refund gives back the token a request took from key's bucket. A bucket
+// swept meanwhile has refilled already, there's nothing to give back to.
+func (b *buckets) refund(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bk, ok := b.byKey[key]; ok {
+		bk.tokens = math.Min(float64(b.rate.Requests), bk.tokens+1)
+	}
+}
+
+// timeFor is how long refilling tokens takes
+func (b *buckets) timeFor(tokens float64) time.Duration {
+	return time.Duration(tokens / b.perSecond * float64(time.Second))
+}
+
+// sweep forgets the buckets that have refilled, which behave just like new
+// ones, so callers that went away don't pile up. It runs at most once a
+// period.
+func (b *buckets) sweep(t time.Time) {
+	if t.Sub(b.lastSweep) < b.rate.Period {
+		return
+	}
+	b.lastSweep = t
+
+	capacity := float64(b.rate.Requests)
+	for key, bk := range b.byKey {
+		if bk.tokens+t.Sub(bk.last).Seconds()*b.perSecond >= capacity {
+			delete(b.byKey, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package ratelimit throttles requests with token buckets, per IP for
+// anonymous callers and per user for authenticated ones.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// limiterKey is where Middleware leaves the limiter of the route for PerUser
+const limiterKey = "ratelimit_limiter"
+
+// Limiter throttles a group of routes, every group having its own
+type Limiter struct {
+	limit     config.RateLimit
+	anonymous *buckets
+	users     *buckets
+}
+
+func New(limit config.RateLimit) *Limiter {
+	return &Limiter{
+		limit:     limit,
+		anonymous: newBuckets(limit.Anonymous),
+		users:     newBuckets(limit.User),
+	}
+}
+
+// Middleware limits requests per IP. Requests carrying a token are instead
+// limited per user by PerUser, once the token is validated: they take a token
+// from their IP before it's validated, which is given back when it turns out
+// to be valid. Bad tokens can't be used to flood the users service, not even
+// by sending them all at once.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(limiterKey, l)
+		ip := c.ClientIP()
+
+		if !admit(c, l.anonymous, l.limit.Anonymous, ip) {
+			return
+		}
+		c.Next()
+		if _, authenticated := c.Get("user_payload"); authenticated && l.anonymous != nil {
+			l.anonymous.refund(ip)
+		}
+	}
+}
+
+// PerUser limits the requests of the user auth.RequiresAuth validated the
+// token of, it's meant to be the handler auth.RequiresAuth calls. Routes
+// outside of a group with a limiter aren't limited.
+func PerUser(h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter, limited := c.Get(limiterKey)
+		payload, _ := c.Get("user_payload")
+		if user, ok := payload.(auth.UserPayload); ok && limited {
+			l := limiter.(*Limiter)
+			if !admit(c, l.users, l.limit.User, strconv.FormatInt(user.Id, 10)) {
+				return
+			}
+		}
+		h(c)
+	}
+}
+
+// admit counts the request against key, telling the caller how much of its
+// limit is left, and turns it down when nothing is
+func admit(c *gin.Context, b *buckets, rate config.Rate, key string) bool {
+	if b == nil {
+		return true
+	}
+
+	q := b.take(key)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(rate.Requests))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(q.remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(seconds(q.reset)))
+	if q.allowed {
+		return true
+	}
+
+	retryAfter := seconds(q.retryAfter)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, rest_errors.NewRestError(
+		fmt.Sprintf("too many requests, retry in %d seconds", retryAfter),
+		http.StatusTooManyRequests,
+		"too_many_requests",
+		nil,
+	))
+	return false
+}
+
+// seconds rounds d up, so retrying after the time advertised always works
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
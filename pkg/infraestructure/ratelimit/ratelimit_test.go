@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// clock freezes now until the test moves it
+func clock(t *testing.T) func(time.Duration) {
+	current := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestBuckets(t *testing.T) {
+	advance := clock(t)
+	b := newBuckets(config.Rate{Requests: 2, Period: time.Minute})
+
+	assert.True(t, b.take("a").allowed)
+	q := b.take("a")
+	assert.True(t, q.allowed)
+	assert.EqualValues(t, 0, q.remaining)
+	assert.EqualValues(t, time.Minute, q.reset)
+
+	q = b.take("a")
+	assert.False(t, q.allowed)
+	assert.EqualValues(t, 30*time.Second, q.retryAfter)
+	assert.True(t, b.take("b").allowed, "keys have buckets of their own")
+
+	advance(30 * time.Second)
+	assert.True(t, b.take("a").allowed)
+	b.refund("a")
+	assert.True(t, b.take("a").allowed, "refunded tokens can be taken again")
+	assert.False(t, b.take("a").allowed)
+
+	advance(2 * time.Minute)
+	b.take("c")
+	assert.Len(t, b.byKey, 1, "refilled buckets are forgotten")
+
+	assert.Nil(t, newBuckets(config.Rate{}))
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	advance := clock(t)
+
+	limiter := New(config.RateLimit{
+		Anonymous: config.Rate{Requests: 1, Period: time.Minute},
+		User:      config.Rate{Requests: 2, Period: time.Minute},
+	})
+	// stands in for auth.RequiresAuth, the token being the user's id
+	requiresAuth := func(h gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			switch c.GetHeader("Authorization") {
+			case "7":
+				c.Set("user_payload", auth.UserPayload{Id: 7})
+			case "8":
+				c.Set("user_payload", auth.UserPayload{Id: 8})
+			default:
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			h(c)
+		}
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	router := gin.New()
+	group := router.Group("", limiter.Middleware())
+	group.GET("/public", ok)
+	group.POST("/private", requiresAuth(PerUser(ok)))
+	// calls /private with a bad token while its own bad token is still being
+	// checked
+	var nested *httptest.ResponseRecorder
+	var call func(method, path, token, ip string) *httptest.ResponseRecorder
+	group.POST("/nested", func(c *gin.Context) {
+		nested = call(http.MethodPost, "/private", "bogus", c.ClientIP())
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+
+	call = func(method, path, token, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Anonymous", func(t *testing.T) {
+		rec := call(http.MethodGet, "/public", "", "10.0.0.1")
+		assert.EqualValues(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, "1", rec.Header().Get("X-RateLimit-Limit"))
+		assert.EqualValues(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+		assert.EqualValues(t, "60", rec.Header().Get("X-RateLimit-Reset"))
+
+		rec = call(http.MethodGet, "/public", "", "10.0.0.1")
+		assert.EqualValues(t, http.StatusTooManyRequests, rec.Code)
+		assert.EqualValues(t, "60", rec.Header().Get("Retry-After"))
+
+		assert.EqualValues(t, http.StatusOK, call(http.MethodGet, "/public", "", "10.0.0.2").Code)
+
+		advance(time.Minute)
+		assert.EqualValues(t, http.StatusOK, call(http.MethodGet, "/public", "", "10.0.0.1").Code)
+	})
+
+	t.Run("PerUser", func(t *testing.T) {
+		// users behind the same IP don't share their limit, nor use the IP's
+		for i := 0; i < 2; i++ {
+			assert.EqualValues(t, http.StatusOK, call(http.MethodPost, "/private", "7", "10.0.1.1").Code)
+			assert.EqualValues(t, http.StatusOK, call(http.MethodPost, "/private", "8", "10.0.1.1").Code)
+		}
+
+		rec := call(http.MethodPost, "/private", "7", "10.0.1.1")
+		assert.EqualValues(t, http.StatusTooManyRequests, rec.Code)
+		assert.EqualValues(t, "2", rec.Header().Get("X-RateLimit-Limit"))
+		assert.EqualValues(t, "30", rec.Header().Get("Retry-After"))
+
+		assert.EqualValues(t, http.StatusOK, call(http.MethodGet, "/public", "", "10.0.1.1").Code,
+			"valid tokens give the IP's limit back")
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, call(http.MethodPost, "/private", "bogus", "10.0.2.1").Code)
+
+		// the failed validation used the IP's limit up, the token isn't even
+		// checked anymore
+		rec := call(http.MethodPost, "/private", "bogus", "10.0.2.1")
+		assert.EqualValues(t, http.StatusTooManyRequests, rec.Code)
+		assert.EqualValues(t, "60", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("ConcurrentInvalidTokens", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, call(http.MethodPost, "/nested", "bogus", "10.0.3.1").Code)
+		assert.EqualValues(t, http.StatusTooManyRequests, nested.Code,
+			"a token in flight already counts against the IP")
+	})
+}
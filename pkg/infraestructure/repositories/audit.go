@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+const saveAuditQuery = `-- save audit entry
+	INSERT INTO audit_log(
+		actor_id,
+		action,
+		entity,
+		entity_id,
+		state_before,
+		state_after,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?, UTC_TIMESTAMP()
+	);
+	`
+
+// saveAudit records a mutation within the transaction that performs it, so the
+// log can't drift from the data it describes
+func saveAudit(ctx context.Context, tx *sql.Tx, actorID int64, action, entity string, entityID int64, before, after interface{}) error {
+	beforeJSON, err := marshalAudit(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalAudit(after)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, saveAuditQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, actorID, action, entity, entityID, beforeJSON, afterJSON)
+	return err
+}
+
+func marshalAudit(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+const getAuditLogQuery = `-- get audit log
+	SELECT
+		id,
+		actor_id,
+		action,
+		entity,
+		entity_id,
+		state_before,
+		state_after,
+		created_at
+	FROM audit_log
+	`
+
+func (r booksRepository) GetAuditLog(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if filter.Entity != "" {
+		conditions = append(conditions, "entity = ?")
+		args = append(args, filter.Entity)
+	}
+	if filter.ActorID != 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.From != "" {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	query := getAuditLogQuery
+	if len(conditions) > 0 {
+		query += "WHERE " + strings.Join(conditions, " AND ") + "\n"
+	}
+	query += "ORDER BY created_at DESC, id DESC\nLIMIT ?;"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	for rows.Next() {
+		var (
+			entry         domain.AuditEntry
+			before, after []byte
+		)
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.Entity,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		if before != nil {
+			entry.Before = before
+		}
+		if after != nil {
+			entry.After = after
+		}
+
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuditLog(t *testing.T) {
+	columns := []string{
+		"id",
+		"actor_id",
+		"action",
+		"entity",
+		"entity_id",
+		"state_before",
+		"state_after",
+		"created_at",
+	}
+
+	t.Run("NoFilters", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows(columns).
+			AddRow(2, 1, "update", "book", 7, []byte(`{"id":7,"price":1999}`), []byte(`{"id":7,"price":2499}`), "2021-12-24 10:00:00").
+			AddRow(1, 1, "create", "author", 3, nil, []byte(`{"id":3}`), "2021-12-23 10:00:00")
+
+		mock.ExpectQuery(regexp.QuoteMeta(getAuditLogQuery + "ORDER BY")).WithArgs(defaultAuditLimit).WillReturnRows(rows)
+
+		entries, err := repo.GetAuditLog(context.Background(), domain.AuditFilter{})
+		assert.Nil(t, err)
+		assert.Len(t, entries, 2)
+		assert.JSONEq(t, `{"id":7,"price":2499}`, string(entries[0].After))
+		assert.Nil(t, entries[1].Before)
+	})
+
+	t.Run("WithFilters", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		filter := domain.AuditFilter{
+			Entity:  "book",
+			ActorID: 1,
+			From:    "2021-12-01 00:00:00",
+			To:      "2022-01-01 00:00:00",
+			Limit:   5000,
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE entity = ? AND actor_id = ? AND created_at >= ? AND created_at < ?")).
+			WithArgs(filter.Entity, filter.ActorID, filter.From, filter.To, maxAuditLimit).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		entries, err := repo.GetAuditLog(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// The following queries back batched loading, their `IN (%s)` clause is
+// expanded with one placeholder per requested id
+
+const (
+	getPublishersByIdsQuery = `-- get publishers by ids
+	SELECT
+		id,
+		name,
+		description,
+		slogan,
+		founded
+	FROM publishers
+	WHERE id IN (%s);
+	`
+
+	getAuthorsByBookIdsQuery = `-- get authors by book ids
+	SELECT
+		authorship.book_id,
+		authors.id,
+		authors.first_name,
+		authors.last_name,
+		authors.biography,
+		authors.birthday,
+		authors.death
+	FROM authorship
+	INNER JOIN authors
+		ON authors.id = authorship.author_id
+	WHERE authorship.book_id IN (%s)
+	ORDER BY authors.id;
+	`
+
+	getAuthorsByPublisherIdsQuery = `-- get authors by publisher ids
+	SELECT
+		published.publisher_id,
+		authors.id,
+		authors.first_name,
+		authors.last_name,
+		authors.biography,
+		authors.birthday,
+		authors.death
+	FROM published
+	INNER JOIN authors
+		ON authors.id = published.author_id
+	WHERE published.publisher_id IN (%s)
+	ORDER BY authors.id;
+	`
+
+	getBooksByAuthorIdsQuery = `-- get books by author ids
+	SELECT
+		authorship.author_id,
+		books.id,
+		books.title,
+		books.original_release,
+		books.description,
+		books.short_description,
+		books.published,
+		books.publisher_id,
+		books.pages,
+		books.seller_id,
+		books.price,
+		books.status
+	FROM authorship
+	INNER JOIN books
+		ON books.id = authorship.book_id
+	WHERE authorship.author_id IN (%s)
+		AND books.status IN ('published', 'out_of_print')
+	ORDER BY books.id;
+	`
+
+	getBooksByPublisherIdsQuery = `-- get books by publisher ids
+	SELECT
+		books.publisher_id,
+		books.id,
+		books.title,
+		books.original_release,
+		books.description,
+		books.short_description,
+		books.published,
+		books.publisher_id,
+		books.pages,
+		books.seller_id,
+		books.price,
+		books.status
+	FROM books
+	WHERE books.publisher_id IN (%s)
+		AND books.status IN ('published', 'out_of_print')
+	ORDER BY books.id;
+	`
+)
+
+// expandIn fills the `IN (%s)` clause of query with as many placeholders as ids
+func expandIn(query string, ids []int64) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	return strings.Replace(query, "%s", placeholders, 1), args
+}
+
+func (r booksRepository) GetPublishersByIds(ctx context.Context, ids []int64) (map[int64]domain.Publisher, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	publishers := make(map[int64]domain.Publisher, len(ids))
+	if len(ids) == 0 {
+		return publishers, nil
+	}
+
+	query, args := expandIn(getPublishersByIdsQuery, ids)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publisher domain.Publisher
+		if err := rows.Scan(
+			&publisher.ID,
+			&publisher.Name,
+			&publisher.Description,
+			&publisher.Slogan,
+			&publisher.Founded,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		publishers[publisher.ID] = publisher
+	}
+
+	return publishers, nil
+}
+
+func (r booksRepository) GetAuthorsByBookIds(ctx context.Context, ids []int64) (map[int64][]domain.Author, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	return r.getAuthorsBy(ctx, getAuthorsByBookIdsQuery, ids)
+}
+
+func (r booksRepository) GetAuthorsByPublisherIds(ctx context.Context, ids []int64) (map[int64][]domain.Author, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	return r.getAuthorsBy(ctx, getAuthorsByPublisherIdsQuery, ids)
+}
+
+func (r booksRepository) getAuthorsBy(ctx context.Context, baseQuery string, ids []int64) (map[int64][]domain.Author, rest_errors.RestErr) {
+	authors := make(map[int64][]domain.Author, len(ids))
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	query, args := expandIn(baseQuery, ids)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key    int64
+			author domain.Author
+		)
+		if err := rows.Scan(
+			&key,
+			&author.ID,
+			&author.FirstName,
+			&author.LastName,
+			&author.Biography,
+			&author.Birthday,
+			&author.Death,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		authors[key] = append(authors[key], author)
+	}
+
+	return authors, nil
+}
+
+func (r booksRepository) GetBooksByAuthorIds(ctx context.Context, ids []int64) (map[int64][]domain.Book, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	return r.getBooksBy(ctx, getBooksByAuthorIdsQuery, ids)
+}
+
+func (r booksRepository) GetBooksByPublisherIds(ctx context.Context, ids []int64) (map[int64][]domain.Book, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	return r.getBooksBy(ctx, getBooksByPublisherIdsQuery, ids)
+}
+
+func (r booksRepository) getBooksBy(ctx context.Context, baseQuery string, ids []int64) (map[int64][]domain.Book, rest_errors.RestErr) {
+	books := make(map[int64][]domain.Book, len(ids))
+	if len(ids) == 0 {
+		return books, nil
+	}
+
+	query, args := expandIn(baseQuery, ids)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key  int64
+			book domain.Book
+		)
+		if err := rows.Scan(
+			&key,
+			&book.ID,
+			&book.Title,
+			&book.OriginalRelease,
+			&book.Description,
+			&book.ShortDescription,
+			&book.Published,
+			&book.PublisherID,
+			&book.Pages,
+			&book.SellerID,
+			&book.Price,
+			&book.Status,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		books[key] = append(books[key], book)
+	}
+
+	return books, nil
+}
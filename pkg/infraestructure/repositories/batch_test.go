@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	batchAuthorColumns = []string{"key", "id", "first_name", "last_name", "biography", "birthday", "death"}
+	batchBookColumns   = []string{
+		"key", "id", "title", "original_release", "description", "short_description",
+		"published", "publisher_id", "pages", "seller_id", "price", "status",
+	}
+)
+
+func TestExpandIn(t *testing.T) {
+	query, args := expandIn("SELECT id FROM books WHERE id IN (%s);", []int64{3, 1, 2})
+	assert.EqualValues(t, "SELECT id FROM books WHERE id IN (?, ?, ?);", query)
+	assert.EqualValues(t, []interface{}{int64(3), int64(1), int64(2)}, args)
+}
+
+func TestGetPublishersByIds(t *testing.T) {
+	query, _ := expandIn(getPublishersByIdsQuery, []int64{7, 8})
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(7, 8).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "name", "description", "slogan", "founded"}).
+				AddRow(7, "Doubleday", "", "", "1897-01-01").
+				AddRow(8, "Sur", "", "", "1931-01-01"),
+		)
+
+		publishers, err := repo.GetPublishersByIds(context.Background(), []int64{7, 8})
+		assert.Nil(t, err)
+		assert.Len(t, publishers, 2)
+		assert.EqualValues(t, "Sur", publishers[8].Name)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NoIds", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		publishers, err := repo.GetPublishersByIds(context.Background(), nil)
+		assert.Nil(t, err)
+		assert.Empty(t, publishers)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(7, 8).WillReturnError(errors.New("connection refused"))
+
+		publishers, err := repo.GetPublishersByIds(context.Background(), []int64{7, 8})
+		assert.Nil(t, publishers)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAuthorsByBookIds(t *testing.T) {
+	query, _ := expandIn(getAuthorsByBookIdsQuery, []int64{1, 2})
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2).WillReturnRows(
+			sqlmock.NewRows(batchAuthorColumns).
+				AddRow(1, 1, "Philip", "Dick", "", "1928-12-16", "1982-03-02").
+				AddRow(2, 1, "Philip", "Dick", "", "1928-12-16", "1982-03-02").
+				AddRow(2, 2, "Ray", "Nelson", "", "1931-10-03", nil),
+		)
+
+		authors, err := repo.GetAuthorsByBookIds(context.Background(), []int64{1, 2})
+		assert.Nil(t, err)
+		assert.Len(t, authors[1], 1)
+		if assert.Len(t, authors[2], 2) {
+			assert.EqualValues(t, "Nelson", authors[2][1].LastName)
+			assert.Nil(t, authors[2][1].Death)
+		}
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2).WillReturnError(errors.New("connection refused"))
+
+		authors, err := repo.GetAuthorsByBookIds(context.Background(), []int64{1, 2})
+		assert.Nil(t, authors)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAuthorsByPublisherIds(t *testing.T) {
+	db, mock := NewMock()
+	repo := booksRepository{db: db}
+
+	query, _ := expandIn(getAuthorsByPublisherIdsQuery, []int64{7})
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(7).WillReturnRows(
+		sqlmock.NewRows(batchAuthorColumns).
+			AddRow(7, 1, "Philip", "Dick", "", "1928-12-16", "1982-03-02"),
+	)
+
+	authors, err := repo.GetAuthorsByPublisherIds(context.Background(), []int64{7})
+	assert.Nil(t, err)
+	assert.Len(t, authors[7], 1)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// the books of an author or a publisher are listed to anyone, so the batched
+// queries only fetch the ones that are public
+func TestBatchedBooksArePublic(t *testing.T) {
+	for _, query := range []string{getBooksByAuthorIdsQuery, getBooksByPublisherIdsQuery} {
+		assert.Contains(t, query, "AND books.status IN ('published', 'out_of_print')")
+	}
+}
+
+func TestGetBooksByAuthorIds(t *testing.T) {
+	query, _ := expandIn(getBooksByAuthorIdsQuery, []int64{1, 2})
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2).WillReturnRows(
+			sqlmock.NewRows(batchBookColumns).
+				AddRow(1, 1, "Ubik", "1969-05-01", "", "", "1969-05-01", 7, 224, 1, 1999, "published").
+				AddRow(1, 2, "Valis", "1981-02-01", "", "", "1981-02-01", 7, 271, 1, 1499, "out_of_print"),
+		)
+
+		books, err := repo.GetBooksByAuthorIds(context.Background(), []int64{1, 2})
+		assert.Nil(t, err)
+		assert.Len(t, books[1], 2)
+		assert.Empty(t, books[2])
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2).WillReturnError(errors.New("connection refused"))
+
+		books, err := repo.GetBooksByAuthorIds(context.Background(), []int64{1, 2})
+		assert.Nil(t, books)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetBooksByPublisherIds(t *testing.T) {
+	db, mock := NewMock()
+	repo := booksRepository{db: db}
+
+	query, _ := expandIn(getBooksByPublisherIdsQuery, []int64{7, 8})
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(7, 8).WillReturnRows(
+		sqlmock.NewRows(batchBookColumns).
+			AddRow(7, 1, "Ubik", "1969-05-01", "", "", "1969-05-01", 7, 224, 1, 1999, "published").
+			AddRow(8, 3, "Ficciones", "1944-01-01", "", "", "1944-01-01", 8, 174, 1, 999, "published"),
+	)
+
+	books, err := repo.GetBooksByPublisherIds(context.Background(), []int64{7, 8})
+	assert.Nil(t, err)
+	assert.Len(t, books[7], 1)
+	if assert.Len(t, books[8], 1) {
+		assert.EqualValues(t, "Ficciones", books[8][0].Title)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
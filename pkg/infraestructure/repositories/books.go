@@ -7,11 +7,14 @@ import (
 
 	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
 	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
 	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/go-sql-driver/mysql"
 )
 
 type booksRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	timeouts config.QueryTimeouts
 }
 
 var (
@@ -19,9 +22,9 @@ var (
 	instanceBooks     booksRepository
 )
 
-func NewBooksRepo(db *sql.DB) ports.BooksRepositoryInterface {
+func NewBooksRepo(db *sql.DB, timeouts config.QueryTimeouts) ports.BooksRepositoryInterface {
 	onceInstanceBooks.Do(func() {
-		instanceBooks = booksRepository{db: db}
+		instanceBooks = booksRepository{db: db, timeouts: timeouts}
 	})
 	return instanceBooks
 }
@@ -38,21 +41,38 @@ INSERT INTO authors(
 );
 `
 
-func (r booksRepository) SaveAuthor(author *domain.Author) rest_errors.RestErr {
-	stmt, err := r.db.Prepare(saveAuthorQuery)
+func (r booksRepository) SaveAuthor(ctx context.Context, actorID int64, author *domain.Author) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, saveAuthorQuery)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return dbError(ctx, err)
 	}
 	defer stmt.Close()
 
-	inserResult, err := stmt.Exec(author.FirstName, author.LastName, author.Biography, author.Birthday, author.Death)
+	inserResult, err := stmt.ExecContext(ctx, author.FirstName, author.LastName, author.Biography, author.Birthday, author.Death)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return dbError(ctx, err)
 	}
 
 	authorId, _ := inserResult.LastInsertId()
 	author.ID = authorId
+	author.Version = 1
+
+	if err := saveAudit(ctx, tx, actorID, domain.ActionCreate, domain.EntityAuthor, authorId, nil, author); err != nil {
+		return dbError(ctx, err)
+	}
 
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
 	return nil
 }
 
@@ -63,7 +83,9 @@ const (
 		last_name,
 		biography,
 		birthday,
-		death
+		death,
+		version,
+		updated_at
 	FROM authors
 	WHERE authors.id = ?;	
 	`
@@ -80,46 +102,53 @@ const (
 		ON authorship.author_id = authors.id
 	INNER JOIN books
 		ON authorship.book_id = books.id
-	WHERE authors.id = ?;
+	WHERE authors.id = ?
+		AND books.status IN ('published', 'out_of_print');
 	`
 )
 
-func (r booksRepository) GetAuthorById(authorID int64) (*domain.AuthorDenormalized, rest_errors.RestErr) {
-	tx, err := r.db.Begin()
+func (r booksRepository) GetAuthorById(ctx context.Context, authorID int64) (*domain.AuthorDenormalized, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer tx.Rollback()
 
 	var author domain.AuthorDenormalized
 
-	authorStmt, err := tx.Prepare(getAuthorById)
+	authorStmt, err := tx.PrepareContext(ctx, getAuthorById)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer authorStmt.Close()
 
-	if err := authorStmt.QueryRow(authorID).Scan(
+	if err := authorStmt.QueryRowContext(ctx, authorID).Scan(
 		&author.Author.FirstName,
 		&author.Author.LastName,
 		&author.Author.Biography,
 		&author.Author.Birthday,
 		&author.Author.Death,
+		&author.Author.Version,
+		&author.Author.UpdatedAt,
 	); err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
+	author.Author.ID = authorID
 
 	//
 
-	booksStmt, err := tx.Prepare(getBooksFromAuthor)
+	booksStmt, err := tx.PrepareContext(ctx, getBooksFromAuthor)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer booksStmt.Close()
 
-	rows, err := booksStmt.Query(authorID)
+	rows, err := booksStmt.QueryContext(ctx, authorID)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 
 	var books domain.Book
@@ -131,22 +160,18 @@ func (r booksRepository) GetAuthorById(authorID int64) (*domain.AuthorDenormaliz
 			&books.ShortDescription,
 			&books.OriginalRelease,
 		); err != nil {
-			return nil, rest_errors.NewInternalServerError(err.Error())
+			return nil, dbError(ctx, err)
 		}
 		author.Books = append(author.Books, books)
 	}
 	rows.Close()
 
 	if err := tx.Commit(); err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	return &author, nil
 }
 
-func (r booksRepository) UpdateAuthor(*domain.Author) rest_errors.RestErr {
-	return nil
-}
-
 const savePublisherQuery = `-- save publisher
 INSERT INTO publishers(
 	name,
@@ -158,21 +183,38 @@ INSERT INTO publishers(
 );
 `
 
-func (r booksRepository) SavePublisher(publisher *domain.Publisher) rest_errors.RestErr {
-	stmt, err := r.db.Prepare(savePublisherQuery)
+func (r booksRepository) SavePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, savePublisherQuery)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return dbError(ctx, err)
 	}
 	defer stmt.Close()
 
-	inserResult, err := stmt.Exec(publisher.Name, publisher.Description, publisher.Slogan, publisher.Founded)
+	inserResult, err := stmt.ExecContext(ctx, publisher.Name, publisher.Description, publisher.Slogan, publisher.Founded)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return dbError(ctx, err)
 	}
 
 	publisherId, _ := inserResult.LastInsertId()
 	publisher.ID = publisherId
+	publisher.Version = 1
 
+	if err := saveAudit(ctx, tx, actorID, domain.ActionCreate, domain.EntityPublisher, publisherId, nil, publisher); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
 	return nil
 }
 
@@ -182,7 +224,9 @@ const (
 		name,        
 		description, 
 		slogan,      
-		founded
+		founded,
+		version,
+		updated_at
 	FROM publishers
 	WHERE id = ?;
 	`
@@ -209,45 +253,52 @@ const (
 		published,
 		pages
 	FROM books
-	WHERE publisher_id = ?;
+	WHERE publisher_id = ?
+		AND status IN ('published', 'out_of_print');
 	`
 )
 
-func (r booksRepository) GetPublisherById(publisherID int64) (*domain.PublisherDenormalized, rest_errors.RestErr) {
-	tx, err := r.db.Begin()
+func (r booksRepository) GetPublisherById(ctx context.Context, publisherID int64) (*domain.PublisherDenormalized, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer tx.Rollback()
 
 	var publisher domain.PublisherDenormalized
 
-	publisherStmt, err := tx.Prepare(getPublisherById)
+	publisherStmt, err := tx.PrepareContext(ctx, getPublisherById)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer publisherStmt.Close()
 
-	if err := publisherStmt.QueryRow(publisherID).Scan(
+	if err := publisherStmt.QueryRowContext(ctx, publisherID).Scan(
 		&publisher.Publisher.Name,
 		&publisher.Publisher.Description,
 		&publisher.Publisher.Slogan,
 		&publisher.Publisher.Founded,
+		&publisher.Publisher.Version,
+		&publisher.Publisher.UpdatedAt,
 	); err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
+	publisher.Publisher.ID = publisherID
 
 	//
 
-	authorsStmt, err := tx.Prepare(getAuthorsForPublisher)
+	authorsStmt, err := tx.PrepareContext(ctx, getAuthorsForPublisher)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer authorsStmt.Close()
 
-	authRows, err := authorsStmt.Query(publisherID)
+	authRows, err := authorsStmt.QueryContext(ctx, publisherID)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 
 	var author domain.Author
@@ -259,7 +310,7 @@ func (r booksRepository) GetPublisherById(publisherID int64) (*domain.PublisherD
 			&author.LastName,
 			&author.Biography,
 		); err != nil {
-			return nil, rest_errors.NewInternalServerError(err.Error())
+			return nil, dbError(ctx, err)
 		}
 
 		publisher.Authors = append(publisher.Authors, author)
@@ -268,16 +319,16 @@ func (r booksRepository) GetPublisherById(publisherID int64) (*domain.PublisherD
 
 	//
 
-	booksStmt, err := tx.Prepare(getBooksForPublisher)
+	booksStmt, err := tx.PrepareContext(ctx, getBooksForPublisher)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer booksStmt.Close()
 
 	var book domain.Book
-	booksRow, err := booksStmt.Query(publisherID)
+	booksRow, err := booksStmt.QueryContext(ctx, publisherID)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 
 	for booksRow.Next() {
@@ -288,23 +339,22 @@ func (r booksRepository) GetPublisherById(publisherID int64) (*domain.PublisherD
 			&book.Published,
 			&book.Pages,
 		); err != nil {
-			return nil, rest_errors.NewInternalServerError(err.Error())
+			return nil, dbError(ctx, err)
 		}
 		publisher.Books = append(publisher.Books, book)
 	}
 	booksRow.Close()
 
-	tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(ctx, err)
+	}
 	return &publisher, nil
 }
 
-func (r booksRepository) UpdatePublisher(*domain.Publisher) rest_errors.RestErr {
-	return nil
-}
-
 const (
 	saveBookQuery = `-- save book
 	INSERT INTO books(
+		isbn,
 		title,
 		original_release,
 		description,
@@ -312,9 +362,12 @@ const (
 		published,
 		publisher_id,
 		pages,
-		seller_id
+		seller_id,
+		price,
+		status,
+		status_changed_at
 	) VALUES (
-		?, ?, ?, ?, ?, ?, ?, ?
+		NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP()
 	);
 	`
 
@@ -327,6 +380,16 @@ const (
 	);
 	`
 
+	savePriceChangeQuery = `-- save price change
+	INSERT INTO price_history(
+		book_id,
+		price,
+		changed_at
+	) VALUES (
+		?, ?, UTC_TIMESTAMP()
+	);
+	`
+
 	savePublishedQuery = `-- save published
 	INSERT IGNORE INTO published(
 		author_id,
@@ -337,20 +400,24 @@ const (
 	`
 )
 
-func (r booksRepository) SaveBook(book *domain.Book) rest_errors.RestErr {
-	tx, err := r.db.BeginTx(context.Background(), nil)
+func (r booksRepository) SaveBook(ctx context.Context, actorID int64, book *domain.Book) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return dbError(ctx, err)
 	}
 
 	defer tx.Rollback()
 
-	bookStmt, err := tx.Prepare(saveBookQuery)
+	bookStmt, err := tx.PrepareContext(ctx, saveBookQuery)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return dbError(ctx, err)
 	}
 
-	inserResult, err := bookStmt.Exec(
+	inserResult, err := bookStmt.ExecContext(ctx,
+		book.ISBN,
 		book.Title,
 		book.OriginalRelease,
 		book.Description,
@@ -359,47 +426,67 @@ func (r booksRepository) SaveBook(book *domain.Book) rest_errors.RestErr {
 		book.PublisherID,
 		book.Pages,
 		book.SellerID,
+		book.Price,
+		book.Status,
 	)
 	if err != nil {
-		return rest_errors.NewInternalServerError(err.Error())
+		return saveBookError(ctx, err)
 	}
 	defer bookStmt.Close()
 
 	bookId, _ := inserResult.LastInsertId()
 	book.ID = bookId
+	book.Version = 1
+
+	historyStmt, err := tx.PrepareContext(ctx, savePriceChangeQuery)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer historyStmt.Close()
+
+	if _, err = historyStmt.ExecContext(ctx, bookId, book.Price); err != nil {
+		return dbError(ctx, err)
+	}
 
 	// TODO: would a better implementation of this use go routines?
 	for k := range book.AuthorID {
-		authorShipStmt, err := tx.Prepare(saveAuthorshipQuery)
+		authorShipStmt, err := tx.PrepareContext(ctx, saveAuthorshipQuery)
 		if err != nil {
-			return rest_errors.NewInternalServerError(err.Error())
+			return dbError(ctx, err)
 		}
 		defer authorShipStmt.Close()
 
-		if _, err = authorShipStmt.Exec(bookId, book.AuthorID[k]); err != nil {
-			return rest_errors.NewInternalServerError(err.Error())
+		if _, err = authorShipStmt.ExecContext(ctx, bookId, book.AuthorID[k]); err != nil {
+			return dbError(ctx, err)
 		}
 
 		//
 
-		publishedStmt, err := tx.Prepare(savePublishedQuery)
+		publishedStmt, err := tx.PrepareContext(ctx, savePublishedQuery)
 		if err != nil {
-			return rest_errors.NewInternalServerError(err.Error())
+			return dbError(ctx, err)
 		}
 		defer publishedStmt.Close()
 
-		if _, err = publishedStmt.Exec(book.AuthorID[k], book.PublisherID); err != nil {
-			return rest_errors.NewInternalServerError(err.Error())
+		if _, err = publishedStmt.ExecContext(ctx, book.AuthorID[k], book.PublisherID); err != nil {
+			return dbError(ctx, err)
 		}
 	}
 
-	tx.Commit()
+	if err := saveAudit(ctx, tx, actorID, domain.ActionCreate, domain.EntityBook, bookId, nil, book); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
 	return nil
 }
 
 const (
 	getBookById = ` -- get book
 	SELECT 
+		COALESCE(books.isbn, ''),
 		books.title,
 		books.original_release,  
 		books.description,      
@@ -407,6 +494,10 @@ const (
 		books.published,        
 		books.pages,            
 		books.seller_id,
+		books.price,
+		books.status,
+		books.version,
+		books.updated_at,
 		publishers.id,
 		publishers.name
 	FROM books
@@ -415,6 +506,19 @@ const (
 	WHERE books.id = ?;         
 	`
 
+	getActivePromotionsForBook = ` -- get active promotions for book
+	SELECT
+		id,
+		kind,
+		amount,
+		starts_at,
+		ends_at
+	FROM promotions
+	WHERE book_id = ?
+		AND starts_at <= UTC_TIMESTAMP()
+		AND ends_at > UTC_TIMESTAMP();
+	`
+
 	getAuthorsForBook = ` -- get authors for book
 	SELECT 
 		authors.id,
@@ -427,23 +531,27 @@ const (
 	`
 )
 
-func (r booksRepository) GetBookById(bookID int64) (*domain.BookDenormalized, rest_errors.RestErr) {
-	tx, err := r.db.Begin()
+func (r booksRepository) GetBookById(ctx context.Context, bookID int64) (*domain.BookDenormalized, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 
 	defer tx.Rollback()
 
-	bookStmt, err := tx.Prepare(getBookById)
+	bookStmt, err := tx.PrepareContext(ctx, getBookById)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer bookStmt.Close()
 
 	var book domain.BookDenormalized
 
-	if err := bookStmt.QueryRow(bookID).Scan(
+	if err := bookStmt.QueryRowContext(ctx, bookID).Scan(
+		&book.Book.ISBN,
 		&book.Book.Title,
 		&book.Book.OriginalRelease,
 		&book.Book.Description,
@@ -451,23 +559,28 @@ func (r booksRepository) GetBookById(bookID int64) (*domain.BookDenormalized, re
 		&book.Book.Published,
 		&book.Book.Pages,
 		&book.Book.SellerID,
+		&book.Book.Price,
+		&book.Book.Status,
+		&book.Book.Version,
+		&book.Book.UpdatedAt,
 		&book.Publisher.ID,
 		&book.Publisher.Name,
 	); err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
+	book.Book.ID = bookID
 
 	//
 
-	authorsStmt, err := r.db.Prepare(getAuthorsForBook)
+	authorsStmt, err := r.db.PrepareContext(ctx, getAuthorsForBook)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 	defer authorsStmt.Close()
 
-	rows, err := authorsStmt.Query(bookID)
+	rows, err := authorsStmt.QueryContext(ctx, bookID)
 	if err != nil {
-		return nil, rest_errors.NewInternalServerError(err.Error())
+		return nil, dbError(ctx, err)
 	}
 
 	var author domain.Author
@@ -478,17 +591,298 @@ func (r booksRepository) GetBookById(bookID int64) (*domain.BookDenormalized, re
 			&author.FirstName,
 			&author.LastName,
 		); err != nil {
-			return nil, rest_errors.NewInternalServerError(err.Error())
+			return nil, dbError(ctx, err)
 		}
 
 		book.Authors = append(book.Authors, author)
 	}
 	rows.Close()
 
-	tx.Commit()
+	//
+
+	promotionsStmt, err := tx.PrepareContext(ctx, getActivePromotionsForBook)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer promotionsStmt.Close()
+
+	promoRows, err := promotionsStmt.QueryContext(ctx, bookID)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	var promotion domain.Promotion
+
+	for promoRows.Next() {
+		if err := promoRows.Scan(
+			&promotion.ID,
+			&promotion.Kind,
+			&promotion.Amount,
+			&promotion.StartsAt,
+			&promotion.EndsAt,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		promotion.BookID = bookID
+
+		book.Promotions = append(book.Promotions, promotion)
+	}
+	promoRows.Close()
+
+	book.EffectivePrice = domain.EffectivePrice(book.Book.Price, book.Promotions)
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(ctx, err)
+	}
 	return &book, nil
 }
 
-func (r booksRepository) UpdateBook(book *domain.Book) rest_errors.RestErr {
+const (
+	updateBookStatusQuery = `-- update book status
+	UPDATE books
+	SET status = ?, status_changed_at = UTC_TIMESTAMP(), version = version + 1, updated_at = UTC_TIMESTAMP()
+	WHERE id = ? AND status = ? AND version = ?;
+	`
+
+	// moderators act on the queue rather than on a version of the book they
+	// fetched, so only the status is checked
+	moderateBookStatusQuery = `-- moderate book status
+	UPDATE books
+	SET status = ?, status_changed_at = UTC_TIMESTAMP(), version = version + 1, updated_at = UTC_TIMESTAMP()
+	WHERE id = ? AND status = ?;
+	`
+)
+
+// UpdateBookStatus only moves the book if it's still in the version and status
+// the transition was validated against
+func (r booksRepository) UpdateBookStatus(ctx context.Context, actorID int64, bookID int64, version int64, from string, to string) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	if err := updateBookStatus(ctx, tx, updateBookStatusQuery, actorID, bookID, from, to, version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
 	return nil
 }
+
+func updateBookStatus(ctx context.Context, tx *sql.Tx, query string, actorID int64, bookID int64, from string, to string, guards ...interface{}) rest_errors.RestErr {
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, append([]interface{}{to, bookID, from}, guards...)...)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return versionConflict()
+	}
+
+	if err := saveAudit(ctx, tx, actorID, domain.ActionUpdate, domain.EntityBook, bookID,
+		domain.BookStatusState{ID: bookID, Status: from},
+		domain.BookStatusState{ID: bookID, Status: to},
+	); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+const (
+	getBookPriceForUpdateQuery = `-- get book price for update
+	SELECT price, version
+	FROM books
+	WHERE id = ?
+	FOR UPDATE;
+	`
+
+	updateBookPriceQuery = `-- update book price
+	UPDATE books
+	SET price = ?, version = version + 1, updated_at = UTC_TIMESTAMP()
+	WHERE id = ?;
+	`
+)
+
+func (r booksRepository) UpdateBookPrice(ctx context.Context, actorID int64, bookID int64, version int64, price int64) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	var previous, current int64
+	if err := tx.QueryRowContext(ctx, getBookPriceForUpdateQuery, bookID).Scan(&previous, &current); err != nil {
+		if err == sql.ErrNoRows {
+			return rest_errors.NewNotFoundError("book not found")
+		}
+		return dbError(ctx, err)
+	}
+	if current != version {
+		return versionConflict()
+	}
+
+	priceStmt, err := tx.PrepareContext(ctx, updateBookPriceQuery)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer priceStmt.Close()
+
+	if _, err := priceStmt.ExecContext(ctx, price, bookID); err != nil {
+		return dbError(ctx, err)
+	}
+
+	historyStmt, err := tx.PrepareContext(ctx, savePriceChangeQuery)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer historyStmt.Close()
+
+	if _, err = historyStmt.ExecContext(ctx, bookID, price); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := saveAudit(ctx, tx, actorID, domain.ActionUpdate, domain.EntityBook, bookID,
+		domain.BookPriceState{ID: bookID, Price: previous},
+		domain.BookPriceState{ID: bookID, Price: price},
+	); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+const getPriceHistoryQuery = `-- get price history
+	SELECT
+		price,
+		changed_at
+	FROM price_history
+	WHERE book_id = ?
+	ORDER BY changed_at, id;
+	`
+
+func (r booksRepository) GetPriceHistory(ctx context.Context, bookID int64) ([]domain.PriceChange, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	stmt, err := r.db.PrepareContext(ctx, getPriceHistoryQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, bookID)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	history := make([]domain.PriceChange, 0)
+	var change domain.PriceChange
+	for rows.Next() {
+		if err := rows.Scan(
+			&change.Price,
+			&change.ChangedAt,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		history = append(history, change)
+	}
+
+	return history, nil
+}
+
+const (
+	savePromotionQuery = `-- save promotion
+INSERT INTO promotions(
+	book_id,
+	kind,
+	amount,
+	starts_at,
+	ends_at
+) VALUES (
+	?, ?, ?, ?, ?
+);
+`
+
+	touchBookQuery = `-- touch book
+	UPDATE books
+	SET version = version + 1, updated_at = UTC_TIMESTAMP()
+	WHERE id = ?;
+	`
+)
+
+func (r booksRepository) SavePromotion(ctx context.Context, actorID int64, promotion *domain.Promotion) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, savePromotionQuery)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	inserResult, err := stmt.ExecContext(ctx,
+		promotion.BookID,
+		promotion.Kind,
+		promotion.Amount,
+		promotion.StartsAt,
+		promotion.EndsAt,
+	)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	promotionId, _ := inserResult.LastInsertId()
+	promotion.ID = promotionId
+
+	// the effective price of the book changes, so does its version
+	if _, err := tx.ExecContext(ctx, touchBookQuery, promotion.BookID); err != nil {
+		return dbError(ctx, err)
+	}
+
+	// promotions belong to the book, so they're audited as changes to it
+	if err := saveAudit(ctx, tx, actorID, domain.ActionUpdate, domain.EntityBook, promotion.BookID, nil, promotion); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+// mysqlDuplicateEntry is the error MySQL fails with on unique key violations
+const mysqlDuplicateEntry = 1062
+
+// saveBookError maps failures writing a book, the only unique column of
+// books being its isbn
+func saveBookError(ctx context.Context, err error) rest_errors.RestErr {
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == mysqlDuplicateEntry {
+		return rest_errors.NewBadRequestError("a book with the same isbn already exists")
+	}
+	return dbError(ctx, err)
+}
@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
+	"net/http"
 	"regexp"
 	"testing"
 
@@ -20,6 +23,29 @@ func NewMock() (*sql.DB, sqlmock.Sqlmock) {
 	return db, mock
 }
 
+func expectAudit(mock sqlmock.Sqlmock, actorID int64, action, entity string, entityID int64) {
+	mock.ExpectPrepare(regexp.QuoteMeta(saveAuditQuery)).ExpectExec().WithArgs(
+		actorID,
+		action,
+		entity,
+		entityID,
+		sqlmock.AnyArg(),
+		sqlmock.AnyArg(),
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+// expectAuditStates is expectAudit checking the states recorded as well
+func expectAuditStates(mock sqlmock.Sqlmock, actorID int64, action, entity string, entityID int64, before, after string) {
+	mock.ExpectPrepare(regexp.QuoteMeta(saveAuditQuery)).ExpectExec().WithArgs(
+		actorID,
+		action,
+		entity,
+		entityID,
+		[]byte(before),
+		[]byte(after),
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
 var (
 	testBook = domain.Book{
 		Title:            "Flow my tears, the policeman said",
@@ -30,7 +56,10 @@ var (
 		PublisherID:      12,
 		Pages:            256,
 		AuthorID:         []int64{0, 1},
+		ISBN:             "9780879972756",
 		SellerID:         1,
+		Price:            1999,
+		Status:           "published",
 	}
 )
 
@@ -51,6 +80,7 @@ func TestSaveAuthor(t *testing.T) {
 
 		author := testAuthor
 		repo := booksRepository{db: db}
+		mock.ExpectBegin()
 		mock.ExpectPrepare(query).ExpectExec().WithArgs(
 			author.FirstName,
 			author.LastName,
@@ -58,8 +88,10 @@ func TestSaveAuthor(t *testing.T) {
 			author.Birthday,
 			author.Death,
 		).WillReturnResult(sqlmock.NewResult(1234, 1))
+		expectAudit(mock, 1, "create", "author", 1234)
+		mock.ExpectCommit()
 
-		err := repo.SaveAuthor(&author)
+		err := repo.SaveAuthor(context.Background(), 1, &author)
 
 		assert.Nil(t, err)
 		assert.EqualValues(t, 1234, author.ID)
@@ -82,14 +114,17 @@ func TestSavePublisher(t *testing.T) {
 		repo := booksRepository{db: db}
 		publisher := testPublisher
 
+		mock.ExpectBegin()
 		mock.ExpectPrepare(query).ExpectExec().WithArgs(
 			publisher.Name,
 			publisher.Description,
 			publisher.Slogan,
 			publisher.Founded,
 		).WillReturnResult(sqlmock.NewResult(12, 1))
+		expectAudit(mock, 1, "create", "publisher", 12)
+		mock.ExpectCommit()
 
-		err := repo.SavePublisher(&publisher)
+		err := repo.SavePublisher(context.Background(), 1, &publisher)
 		assert.Nil(t, err)
 		assert.EqualValues(t, 12, publisher.ID)
 	})
@@ -99,15 +134,12 @@ func TestSaveBook(t *testing.T) {
 	queryBook := regexp.QuoteMeta(saveBookQuery)
 	queryAuthorShip := regexp.QuoteMeta(saveAuthorshipQuery)
 	queryPublished := regexp.QuoteMeta(savePublishedQuery)
+	queryPriceChange := regexp.QuoteMeta(savePriceChangeQuery)
 
-	t.Run("NoError", func(t *testing.T) {
-		db, mock := NewMock()
-
-		repo := booksRepository{db: db}
-		book := testBook
-
+	expectSave := func(mock sqlmock.Sqlmock, book domain.Book) {
 		mock.ExpectBegin()
 		mock.ExpectPrepare(queryBook).ExpectExec().WithArgs(
+			book.ISBN,
 			book.Title,
 			book.OriginalRelease,
 			book.Description,
@@ -116,8 +148,15 @@ func TestSaveBook(t *testing.T) {
 			book.PublisherID,
 			book.Pages,
 			book.SellerID,
+			book.Price,
+			book.Status,
 		).WillReturnResult(sqlmock.NewResult(69, 1))
 
+		mock.ExpectPrepare(queryPriceChange).ExpectExec().WithArgs(
+			69,
+			book.Price,
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+
 		for k := range book.AuthorID {
 			mock.ExpectPrepare(queryAuthorShip).ExpectExec().WithArgs(
 				69, // Id of the inserted book
@@ -130,20 +169,47 @@ func TestSaveBook(t *testing.T) {
 			).WillReturnResult(sqlmock.NewResult(1, 1))
 		}
 
+		expectAudit(mock, book.SellerID, "create", "book", 69)
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+
+		repo := booksRepository{db: db}
+		book := testBook
+
+		expectSave(mock, book)
 		mock.ExpectCommit()
 
-		err := repo.SaveBook(&book)
+		err := repo.SaveBook(context.Background(), book.SellerID, &book)
 		assert.Nil(t, err)
 		assert.EqualValues(t, 69, book.ID)
 	})
+
+	t.Run("CommitError", func(t *testing.T) {
+		db, mock := NewMock()
+
+		repo := booksRepository{db: db}
+		book := testBook
+
+		expectSave(mock, book)
+		mock.ExpectCommit().WillReturnError(errors.New("connection reset"))
+
+		err := repo.SaveBook(context.Background(), book.SellerID, &book)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestGetBookByID(t *testing.T) {
 	queryBook := regexp.QuoteMeta(getBookById)
 	queryAuthors := regexp.QuoteMeta(getAuthorsForBook)
+	queryPromotions := regexp.QuoteMeta(getActivePromotionsForBook)
 
 	t.Run("NoError", func(t *testing.T) {
 		bookRow := sqlmock.NewRows([]string{
+			"books.isbn",
 			"books.title",
 			"books.original_release",
 			"books.description",
@@ -151,10 +217,15 @@ func TestGetBookByID(t *testing.T) {
 			"books.published",
 			"books.pages",
 			"books.seller_id",
+			"books.price",
+			"books.status",
+			"books.version",
+			"books.updated_at",
 			"publishers.id",
 			"publishers.name",
 		}).
 			AddRow(
+				testBook.ISBN,
 				testBook.Title,
 				testBook.OriginalRelease,
 				testBook.Description,
@@ -162,6 +233,10 @@ func TestGetBookByID(t *testing.T) {
 				testBook.Published,
 				testBook.Pages,
 				testBook.SellerID,
+				testBook.Price,
+				testBook.Status,
+				3,
+				"2021-12-20 10:00:00",
 				testBook.PublisherID,
 				"penguin",
 			)
@@ -180,6 +255,26 @@ func TestGetBookByID(t *testing.T) {
 			"Borges",
 		)
 
+		promotionRows := sqlmock.NewRows([]string{
+			"id",
+			"kind",
+			"amount",
+			"starts_at",
+			"ends_at",
+		}).AddRow(
+			1,
+			"percentage",
+			10,
+			"2021-12-01 00:00:00",
+			"2022-01-01 00:00:00",
+		).AddRow(
+			2,
+			"fixed",
+			500,
+			"2021-12-20 00:00:00",
+			"2021-12-27 00:00:00",
+		)
+
 		db, mock := NewMock()
 		repo := booksRepository{db: db}
 
@@ -187,9 +282,113 @@ func TestGetBookByID(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectPrepare(queryBook).ExpectQuery().WithArgs(bookID).WillReturnRows(bookRow)
 		mock.ExpectPrepare(queryAuthors).ExpectQuery().WithArgs(bookID).WillReturnRows(authorRows)
+		mock.ExpectPrepare(queryPromotions).ExpectQuery().WithArgs(bookID).WillReturnRows(promotionRows)
+		mock.ExpectCommit()
+
+		book, err := repo.GetBookById(context.Background(), int64(bookID))
+		assert.Nil(t, err)
+		assert.Len(t, book.Promotions, 2)
+		assert.EqualValues(t, 1499, book.EffectivePrice)
+		assert.EqualValues(t, 3, book.Book.Version)
+	})
+}
+
+func TestUpdateBookStatus(t *testing.T) {
+	query := regexp.QuoteMeta(updateBookStatusQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().WithArgs("pending_review", 7, "draft", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectAuditStates(mock, 1, "update", "book", 7, `{"id":7,"status":"draft"}`, `{"id":7,"status":"pending_review"}`)
 		mock.ExpectCommit()
 
-		_, err := repo.GetBookById(int64(bookID))
+		err := repo.UpdateBookStatus(context.Background(), 1, 7, 2, "draft", "pending_review")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(query).ExpectExec().WithArgs("pending_review", 7, "draft", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.UpdateBookStatus(context.Background(), 1, 7, 2, "draft", "pending_review")
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusPreconditionFailed, err.Status())
+	})
+}
+
+func TestUpdateBookPrice(t *testing.T) {
+	queryCurrent := regexp.QuoteMeta(getBookPriceForUpdateQuery)
+	queryPrice := regexp.QuoteMeta(updateBookPriceQuery)
+	queryPriceChange := regexp.QuoteMeta(savePriceChangeQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(queryCurrent).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"price", "version"}).AddRow(1999, 2))
+		mock.ExpectPrepare(queryPrice).ExpectExec().WithArgs(2499, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectPrepare(queryPriceChange).ExpectExec().WithArgs(7, 2499).WillReturnResult(sqlmock.NewResult(1, 1))
+		expectAuditStates(mock, 1, "update", "book", 7, `{"id":7,"price":1999}`, `{"id":7,"price":2499}`)
+		mock.ExpectCommit()
+
+		err := repo.UpdateBookPrice(context.Background(), 1, 7, 2, 2499)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(queryCurrent).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"price", "version"}))
+		mock.ExpectRollback()
+
+		err := repo.UpdateBookPrice(context.Background(), 1, 7, 2, 2499)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusNotFound, err.Status())
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(queryCurrent).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"price", "version"}).AddRow(1999, 3))
+		mock.ExpectRollback()
+
+		err := repo.UpdateBookPrice(context.Background(), 1, 7, 2, 2499)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusPreconditionFailed, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	query := regexp.QuoteMeta(getPriceHistoryQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows([]string{"price", "changed_at"}).
+			AddRow(1999, "2021-12-01 10:00:00").
+			AddRow(2499, "2021-12-20 18:30:00")
+
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(7).WillReturnRows(rows)
+
+		history, err := repo.GetPriceHistory(context.Background(), 7)
 		assert.Nil(t, err)
+		assert.Len(t, history, 2)
+		assert.EqualValues(t, 2499, history[1].Price)
 	})
 }
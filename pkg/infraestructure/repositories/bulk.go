@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// The following queries back bulk creation, their `VALUES %s` clause is
+// expanded with one row of placeholders per inserted record
+
+const (
+	saveBooksQuery = `-- save books
+	INSERT INTO books(
+		isbn,
+		title,
+		original_release,
+		description,
+		short_description,
+		published,
+		publisher_id,
+		pages,
+		seller_id,
+		price,
+		status,
+		status_changed_at
+	) VALUES %s;
+	`
+	saveBooksRow = "(NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())"
+
+	savePriceChangesQuery = `-- save price changes
+	INSERT INTO price_history(
+		book_id,
+		price,
+		changed_at
+	) VALUES %s;
+	`
+	savePriceChangesRow = "(?, ?, UTC_TIMESTAMP())"
+
+	saveAuthorshipsQuery = `-- save authorships
+	INSERT INTO authorship(
+		book_id,
+		author_id
+	) VALUES %s;
+	`
+	savePublishedsQuery = `-- save publisheds
+	INSERT IGNORE INTO published(
+		author_id,
+		publisher_id
+	) VALUES %s;
+	`
+	savePairRow = "(?, ?)"
+
+	saveAuditsQuery = `-- save audit entries
+	INSERT INTO audit_log(
+		actor_id,
+		action,
+		entity,
+		entity_id,
+		state_before,
+		state_after,
+		created_at
+	) VALUES %s;
+	`
+	saveAuditsRow = "(?, ?, ?, ?, NULL, ?, UTC_TIMESTAMP())"
+
+	getAutoIncrementIncrementQuery = `-- get auto increment increment
+	SELECT @@SESSION.auto_increment_increment;
+	`
+)
+
+func expandValues(query string, row string, rows int) string {
+	values := strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+	return strings.Replace(query, "%s", values, 1)
+}
+
+// SaveBooks creates every book or none of them, whatever the amount of books
+// it takes a fixed number of round trips
+func (r booksRepository) SaveBooks(ctx context.Context, actorID int64, books []domain.Book) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	if len(books) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	if err := saveBooks(ctx, tx, actorID, books); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+const (
+	saveImportCheckpointQuery = `-- save import checkpoint
+	INSERT INTO import_checkpoints(
+		name,
+		line,
+		updated_at
+	) VALUES (
+		?, ?, UTC_TIMESTAMP()
+	) ON DUPLICATE KEY UPDATE line = VALUES(line), updated_at = VALUES(updated_at);
+	`
+
+	getImportCheckpointQuery = `-- get import checkpoint
+	SELECT line
+	FROM import_checkpoints
+	WHERE name = ?;
+	`
+)
+
+// SaveImportBatch is SaveBooks recording how far the import went in the same
+// transaction, the books and the checkpoint are saved together or not at all
+func (r booksRepository) SaveImportBatch(ctx context.Context, actorID int64, books []domain.Book, checkpoint domain.ImportCheckpoint) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	if len(books) > 0 {
+		if err := saveBooks(ctx, tx, actorID, books); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, saveImportCheckpointQuery, checkpoint.Name, checkpoint.Line); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+// GetImportCheckpoint returns the last line the import tracked as name saved,
+// 0 when it never saved any
+func (r booksRepository) GetImportCheckpoint(ctx context.Context, name string) (int, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var line int
+	if err := r.db.QueryRowContext(ctx, getImportCheckpointQuery, name).Scan(&line); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, dbError(ctx, err)
+	}
+	return line, nil
+}
+
+// saveBooks inserts books within tx.
+//
+// Ids are worked out from the first id of the multi-row insert. InnoDB
+// allocates the ids of an insert whose row count is known upfront in one go,
+// whatever the innodb_autoinc_lock_mode, each auto_increment_increment apart
+// from the previous one; clusters such as Galera or group replication set it
+// above 1.
+func saveBooks(ctx context.Context, tx *sql.Tx, actorID int64, books []domain.Book) rest_errors.RestErr {
+	bookArgs := make([]interface{}, 0, len(books)*11)
+	for _, book := range books {
+		bookArgs = append(bookArgs,
+			book.ISBN,
+			book.Title,
+			book.OriginalRelease,
+			book.Description,
+			book.ShortDescription,
+			book.Published,
+			book.PublisherID,
+			book.Pages,
+			book.SellerID,
+			book.Price,
+			book.Status,
+		)
+	}
+
+	result, err := tx.ExecContext(ctx, expandValues(saveBooksQuery, saveBooksRow, len(books)), bookArgs...)
+	if err != nil {
+		return saveBookError(ctx, err)
+	}
+	firstID, err := result.LastInsertId()
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	var step int64
+	if err := tx.QueryRowContext(ctx, getAutoIncrementIncrementQuery).Scan(&step); err != nil {
+		return dbError(ctx, err)
+	}
+
+	var priceArgs, authorshipArgs, publishedArgs []interface{}
+	for i := range books {
+		books[i].ID = firstID + int64(i)*step
+		books[i].Version = 1
+
+		priceArgs = append(priceArgs, books[i].ID, books[i].Price)
+		for _, authorID := range books[i].AuthorID {
+			authorshipArgs = append(authorshipArgs, books[i].ID, authorID)
+			publishedArgs = append(publishedArgs, authorID, books[i].PublisherID)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, expandValues(savePriceChangesQuery, savePriceChangesRow, len(books)), priceArgs...); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if authorships := len(authorshipArgs) / 2; authorships > 0 {
+		if _, err := tx.ExecContext(ctx, expandValues(saveAuthorshipsQuery, savePairRow, authorships), authorshipArgs...); err != nil {
+			return dbError(ctx, err)
+		}
+		if _, err := tx.ExecContext(ctx, expandValues(savePublishedsQuery, savePairRow, authorships), publishedArgs...); err != nil {
+			return dbError(ctx, err)
+		}
+	}
+
+	auditArgs := make([]interface{}, 0, len(books)*5)
+	for i := range books {
+		after, err := marshalAudit(books[i])
+		if err != nil {
+			return dbError(ctx, err)
+		}
+		auditArgs = append(auditArgs, actorID, domain.ActionCreate, domain.EntityBook, books[i].ID, after)
+	}
+	if _, err := tx.ExecContext(ctx, expandValues(saveAuditsQuery, saveAuditsRow, len(books)), auditArgs...); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveBooks(t *testing.T) {
+	queryBooks := regexp.QuoteMeta(expandValues(saveBooksQuery, saveBooksRow, 2))
+	queryPrices := regexp.QuoteMeta(expandValues(savePriceChangesQuery, savePriceChangesRow, 2))
+	queryAuthorships := regexp.QuoteMeta(expandValues(saveAuthorshipsQuery, savePairRow, 3))
+	queryPublisheds := regexp.QuoteMeta(expandValues(savePublishedsQuery, savePairRow, 3))
+	queryAudits := regexp.QuoteMeta(expandValues(saveAuditsQuery, saveAuditsRow, 2))
+	queryIncrement := regexp.QuoteMeta(getAutoIncrementIncrementQuery)
+	increment := func(step int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"@@SESSION.auto_increment_increment"}).AddRow(step)
+	}
+
+	newBooks := func() []domain.Book {
+		second := testBook
+		second.Title = "Ficciones"
+		second.AuthorID = []int64{1}
+		return []domain.Book{testBook, second}
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+		books := newBooks()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(queryBooks).WillReturnResult(sqlmock.NewResult(40, 2))
+		mock.ExpectQuery(queryIncrement).WillReturnRows(increment(1))
+		mock.ExpectExec(queryPrices).WithArgs(40, testBook.Price, 41, testBook.Price).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectExec(queryAuthorships).WithArgs(40, 0, 40, 1, 41, 1).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(queryPublisheds).WithArgs(0, 12, 1, 12, 1, 12).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(queryAudits).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+
+		err := repo.SaveBooks(context.Background(), 1, books)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 40, books[0].ID)
+		assert.EqualValues(t, 41, books[1].ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AutoIncrementStep", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+		books := newBooks()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(queryBooks).WillReturnResult(sqlmock.NewResult(40, 2))
+		mock.ExpectQuery(queryIncrement).WillReturnRows(increment(3))
+		mock.ExpectExec(queryPrices).WithArgs(40, testBook.Price, 43, testBook.Price).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectExec(queryAuthorships).WithArgs(40, 0, 40, 1, 43, 1).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(queryPublisheds).WithArgs(0, 12, 1, 12, 1, 12).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(queryAudits).WithArgs(
+			1, "create", "book", 40, sqlmock.AnyArg(),
+			1, "create", "book", 43, sqlmock.AnyArg(),
+		).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+
+		err := repo.SaveBooks(context.Background(), 1, books)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 40, books[0].ID)
+		assert.EqualValues(t, 43, books[1].ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(queryBooks).WillReturnError(errors.New("foreign key constraint fails"))
+		mock.ExpectRollback()
+
+		err := repo.SaveBooks(context.Background(), 1, newBooks())
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveImportBatch(t *testing.T) {
+	queryBooks := regexp.QuoteMeta(expandValues(saveBooksQuery, saveBooksRow, 1))
+	queryPrices := regexp.QuoteMeta(expandValues(savePriceChangesQuery, savePriceChangesRow, 1))
+	queryAuthorships := regexp.QuoteMeta(expandValues(saveAuthorshipsQuery, savePairRow, 2))
+	queryPublisheds := regexp.QuoteMeta(expandValues(savePublishedsQuery, savePairRow, 2))
+	queryAudits := regexp.QuoteMeta(expandValues(saveAuditsQuery, saveAuditsRow, 1))
+	queryIncrement := regexp.QuoteMeta(getAutoIncrementIncrementQuery)
+	queryCheckpoint := regexp.QuoteMeta(saveImportCheckpointQuery)
+	checkpoint := domain.ImportCheckpoint{Name: "/imports/catalog.jsonl", Line: 50}
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(queryBooks).WillReturnResult(sqlmock.NewResult(40, 1))
+		mock.ExpectQuery(queryIncrement).WillReturnRows(sqlmock.NewRows([]string{"@@SESSION.auto_increment_increment"}).AddRow(1))
+		mock.ExpectExec(queryPrices).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(queryAuthorships).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(queryPublisheds).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(queryAudits).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(queryCheckpoint).WithArgs("/imports/catalog.jsonl", 50).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.SaveImportBatch(context.Background(), 1, []domain.Book{testBook}, checkpoint)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NoBooks", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(queryCheckpoint).WithArgs("/imports/catalog.jsonl", 50).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.SaveImportBatch(context.Background(), 1, nil, checkpoint)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CheckpointError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(queryBooks).WillReturnResult(sqlmock.NewResult(40, 1))
+		mock.ExpectQuery(queryIncrement).WillReturnRows(sqlmock.NewRows([]string{"@@SESSION.auto_increment_increment"}).AddRow(1))
+		mock.ExpectExec(queryPrices).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(queryAuthorships).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(queryPublisheds).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(queryAudits).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(queryCheckpoint).WillReturnError(errors.New("table doesn't exist"))
+		mock.ExpectRollback()
+
+		err := repo.SaveImportBatch(context.Background(), 1, []domain.Book{testBook}, checkpoint)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetImportCheckpoint(t *testing.T) {
+	query := regexp.QuoteMeta(getImportCheckpointQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(query).WithArgs("/imports/catalog.jsonl").WillReturnRows(sqlmock.NewRows([]string{"line"}).AddRow(50))
+
+		line, err := repo.GetImportCheckpoint(context.Background(), "/imports/catalog.jsonl")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 50, line)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NeverSaved", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(query).WithArgs("/imports/catalog.jsonl").WillReturnError(sql.ErrNoRows)
+
+		line, err := repo.GetImportCheckpoint(context.Background(), "/imports/catalog.jsonl")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 0, line)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
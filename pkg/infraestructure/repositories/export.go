@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// exportBooksQuery has one row per authorship, rows of the same book come one
+// after the other so they're folded into an entry without buffering more
+// than a book at a time
+const exportBooksQuery = `-- export books
+	SELECT
+		books.id,
+		COALESCE(books.isbn, ''),
+		books.title,
+		books.original_release,
+		books.description,
+		books.short_description,
+		books.published,
+		books.pages,
+		books.seller_id,
+		books.price,
+		books.status,
+		books.version,
+		books.updated_at,
+		publishers.id,
+		publishers.name,
+		authors.id,
+		authors.first_name,
+		authors.last_name
+	FROM books
+	INNER JOIN publishers
+		ON publishers.id = books.publisher_id
+	LEFT JOIN authorship
+		ON authorship.book_id = books.id
+	LEFT JOIN authors
+		ON authors.id = authorship.author_id
+	ORDER BY books.id, authors.id;
+	`
+
+// ExportBooks calls fn with every book of the catalog, whatever its status,
+// in id order. Rows are streamed from the database and stop being read as
+// soon as fn fails.
+func (r booksRepository) ExportBooks(ctx context.Context, fn func(domain.CatalogEntry) error) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Export)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, exportBooksQuery)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	var entry *domain.CatalogEntry
+	for rows.Next() {
+		var (
+			book      domain.Book
+			publisher domain.Publisher
+			authorID  *int64
+			firstName *string
+			lastName  *string
+		)
+		if err := rows.Scan(
+			&book.ID,
+			&book.ISBN,
+			&book.Title,
+			&book.OriginalRelease,
+			&book.Description,
+			&book.ShortDescription,
+			&book.Published,
+			&book.Pages,
+			&book.SellerID,
+			&book.Price,
+			&book.Status,
+			&book.Version,
+			&book.UpdatedAt,
+			&publisher.ID,
+			&publisher.Name,
+			&authorID,
+			&firstName,
+			&lastName,
+		); err != nil {
+			return dbError(ctx, err)
+		}
+
+		if entry == nil || entry.Book.ID != book.ID {
+			if entry != nil {
+				if err := fn(*entry); err != nil {
+					return dbError(ctx, err)
+				}
+			}
+			book.PublisherID = publisher.ID
+			entry = &domain.CatalogEntry{Book: book, Publisher: publisher}
+		}
+
+		if authorID != nil {
+			entry.Book.AuthorID = append(entry.Book.AuthorID, *authorID)
+			entry.Authors = append(entry.Authors, domain.Author{ID: *authorID, FirstName: *firstName, LastName: *lastName})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if entry != nil {
+		if err := fn(*entry); err != nil {
+			return dbError(ctx, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportBooks(t *testing.T) {
+	db, mock := NewMock()
+	repo := booksRepository{db: db}
+
+	rows := sqlmock.NewRows([]string{
+		"books.id",
+		"books.isbn",
+		"books.title",
+		"books.original_release",
+		"books.description",
+		"books.short_description",
+		"books.published",
+		"books.pages",
+		"books.seller_id",
+		"books.price",
+		"books.status",
+		"books.version",
+		"books.updated_at",
+		"publishers.id",
+		"publishers.name",
+		"authors.id",
+		"authors.first_name",
+		"authors.last_name",
+	}).
+		AddRow(1, "9780802130303", "Ficciones", "1944-01-01", "", "", "1944-01-01", 174, 1, 1999, "published", 1, "2021-12-20 10:00:00", 2, "Sur", 3, "Jorge Luis", "Borges").
+		AddRow(1, "9780802130303", "Ficciones", "1944-01-01", "", "", "1944-01-01", 174, 1, 1999, "published", 1, "2021-12-20 10:00:00", 2, "Sur", 4, "Adolfo", "Bioy Casares").
+		AddRow(5, "", "Anonymous", "1900-01-01", "", "", "1900-01-01", 80, 1, 500, "draft", 1, "2021-12-20 10:00:00", 2, "Sur", nil, nil, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(exportBooksQuery)).WillReturnRows(rows)
+
+	var entries []domain.CatalogEntry
+	err := repo.ExportBooks(context.Background(), func(entry domain.CatalogEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+
+	assert.EqualValues(t, []int64{3, 4}, entries[0].Book.AuthorID)
+	assert.EqualValues(t, "Bioy Casares", entries[0].Authors[1].LastName)
+	assert.EqualValues(t, "Sur", entries[0].Publisher.Name)
+	assert.EqualValues(t, 2, entries[0].Book.PublisherID)
+
+	assert.EqualValues(t, 5, entries[1].Book.ID)
+	assert.Empty(t, entries[1].Authors)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
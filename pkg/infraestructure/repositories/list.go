@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+const (
+	listAuthorsQuery = `-- list authors
+	SELECT
+		id,
+		first_name,
+		last_name,
+		biography,
+		birthday,
+		death
+	FROM authors
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?;
+	`
+
+	listPublishersQuery = `-- list publishers
+	SELECT
+		id,
+		name,
+		description,
+		slogan,
+		founded
+	FROM publishers
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?;
+	`
+
+	listBooksQuery = `-- list books
+	SELECT
+		id,
+		title,
+		original_release,
+		short_description,
+		published,
+		publisher_id,
+		pages,
+		seller_id,
+		price,
+		status
+	FROM books
+	WHERE id > ?
+		AND status IN ('published', 'out_of_print')
+	ORDER BY id
+	LIMIT ?;
+	`
+
+	listSellerBooksQuery = `-- list seller books
+	SELECT
+		id,
+		title,
+		original_release,
+		short_description,
+		published,
+		publisher_id,
+		pages,
+		seller_id,
+		price,
+		status
+	FROM books
+	WHERE seller_id = ?
+		AND id > ?
+		AND (? = '' OR status = ?)
+		AND (? = FALSE OR status IN ('published', 'out_of_print'))
+	ORDER BY id
+	LIMIT ?;
+	`
+)
+
+func (r booksRepository) ListAuthors(ctx context.Context, opts domain.ListOptions) ([]domain.Author, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	opts = opts.Normalize()
+
+	stmt, err := r.db.PrepareContext(ctx, listAuthorsQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, opts.AfterID, opts.Limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	authors := make([]domain.Author, 0, opts.Limit)
+	for rows.Next() {
+		var author domain.Author
+		if err := rows.Scan(
+			&author.ID,
+			&author.FirstName,
+			&author.LastName,
+			&author.Biography,
+			&author.Birthday,
+			&author.Death,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		authors = append(authors, author)
+	}
+
+	return authors, nil
+}
+
+func (r booksRepository) ListPublishers(ctx context.Context, opts domain.ListOptions) ([]domain.Publisher, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	opts = opts.Normalize()
+
+	stmt, err := r.db.PrepareContext(ctx, listPublishersQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, opts.AfterID, opts.Limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	publishers := make([]domain.Publisher, 0, opts.Limit)
+	for rows.Next() {
+		var publisher domain.Publisher
+		if err := rows.Scan(
+			&publisher.ID,
+			&publisher.Name,
+			&publisher.Description,
+			&publisher.Slogan,
+			&publisher.Founded,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		publishers = append(publishers, publisher)
+	}
+
+	return publishers, nil
+}
+
+// ListBooks only lists books that are public
+func (r booksRepository) ListBooks(ctx context.Context, opts domain.ListOptions) ([]domain.Book, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	opts = opts.Normalize()
+
+	stmt, err := r.db.PrepareContext(ctx, listBooksQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, opts.AfterID, opts.Limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	return scanListedBooks(ctx, rows, opts.Limit)
+}
+
+// ListSellerBooks lists the books of a seller, the ones that aren't public
+// too unless filter says otherwise
+func (r booksRepository) ListSellerBooks(ctx context.Context, filter domain.SellerBooksFilter, opts domain.ListOptions) ([]domain.Book, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	opts = opts.Normalize()
+
+	stmt, err := r.db.PrepareContext(ctx, listSellerBooksQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, filter.SellerID, opts.AfterID, filter.Status, filter.Status, filter.PublicOnly, opts.Limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	return scanListedBooks(ctx, rows, opts.Limit)
+}
+
+// scanListedBooks reads the rows of the book listings, limit being the page
+// size the query was run with
+func scanListedBooks(ctx context.Context, rows *sql.Rows, limit int) ([]domain.Book, rest_errors.RestErr) {
+	books := make([]domain.Book, 0, limit)
+	for rows.Next() {
+		var book domain.Book
+		if err := rows.Scan(
+			&book.ID,
+			&book.Title,
+			&book.OriginalRelease,
+			&book.ShortDescription,
+			&book.Published,
+			&book.PublisherID,
+			&book.Pages,
+			&book.SellerID,
+			&book.Price,
+			&book.Status,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		books = append(books, book)
+	}
+
+	return books, nil
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListBooks(t *testing.T) {
+	query := regexp.QuoteMeta(listBooksQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows([]string{
+			"id",
+			"title",
+			"original_release",
+			"short_description",
+			"published",
+			"publisher_id",
+			"pages",
+			"seller_id",
+			"price",
+			"status",
+		}).AddRow(
+			11,
+			testBook.Title,
+			testBook.OriginalRelease,
+			testBook.ShortDescription,
+			testBook.Published,
+			testBook.PublisherID,
+			testBook.Pages,
+			testBook.SellerID,
+			testBook.Price,
+			testBook.Status,
+		)
+
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(10, domain.MaxListLimit).WillReturnRows(rows)
+
+		books, err := repo.ListBooks(context.Background(), domain.ListOptions{AfterID: 10, Limit: 500})
+		assert.Nil(t, err)
+		assert.Len(t, books, 1)
+		assert.EqualValues(t, 11, books[0].ID)
+	})
+}
+
+func TestListSellerBooks(t *testing.T) {
+	query := regexp.QuoteMeta(listSellerBooksQuery)
+	columns := []string{
+		"id",
+		"title",
+		"original_release",
+		"short_description",
+		"published",
+		"publisher_id",
+		"pages",
+		"seller_id",
+		"price",
+		"status",
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows(columns).AddRow(
+			12,
+			testBook.Title,
+			testBook.OriginalRelease,
+			testBook.ShortDescription,
+			testBook.Published,
+			testBook.PublisherID,
+			testBook.Pages,
+			7,
+			testBook.Price,
+			domain.StatusDraft,
+		)
+
+		mock.ExpectPrepare(query).ExpectQuery().
+			WithArgs(7, 0, domain.StatusDraft, domain.StatusDraft, false, domain.DefaultListLimit).
+			WillReturnRows(rows)
+
+		books, err := repo.ListSellerBooks(context.Background(), domain.SellerBooksFilter{SellerID: 7, Status: domain.StatusDraft}, domain.ListOptions{})
+		assert.Nil(t, err)
+		if assert.Len(t, books, 1) {
+			assert.EqualValues(t, domain.StatusDraft, books[0].Status)
+		}
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("PublicOnly", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectPrepare(query).ExpectQuery().
+			WithArgs(7, 10, "", "", true, 5).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		books, err := repo.ListSellerBooks(context.Background(), domain.SellerBooksFilter{SellerID: 7, PublicOnly: true}, domain.ListOptions{AfterID: 10, Limit: 5})
+		assert.Nil(t, err)
+		assert.Empty(t, books)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectPrepare(query).ExpectQuery().WillReturnError(errors.New("connection refused"))
+
+		books, err := repo.ListSellerBooks(context.Background(), domain.SellerBooksFilter{SellerID: 7}, domain.ListOptions{})
+		assert.Nil(t, books)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+	})
+}
+
+func TestListAuthors(t *testing.T) {
+	query := regexp.QuoteMeta(listAuthorsQuery)
+
+	t.Run("DefaultLimit", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows([]string{
+			"id",
+			"first_name",
+			"last_name",
+			"biography",
+			"birthday",
+			"death",
+		}).AddRow(1, "Philip", "Dick", "a weird biography ...", "1928-12-16", "1982-03-02").
+			AddRow(2, "Ursula", "Le Guin", "another biography", "1929-10-21", nil)
+
+		mock.ExpectPrepare(query).ExpectQuery().WithArgs(0, domain.DefaultListLimit).WillReturnRows(rows)
+
+		authors, err := repo.ListAuthors(context.Background(), domain.ListOptions{})
+		assert.Nil(t, err)
+		assert.Len(t, authors, 2)
+		assert.Nil(t, authors[1].Death)
+	})
+}
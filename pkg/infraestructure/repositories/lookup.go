@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// Names aren't unique, when several records share one the oldest is returned.
+// ISBNs are, and are looked up normalized.
+
+const (
+	getAuthorByNameQuery = `-- get author by name
+	SELECT
+		id,
+		first_name,
+		last_name,
+		biography,
+		birthday,
+		death,
+		version,
+		updated_at
+	FROM authors
+	WHERE first_name = ? AND last_name = ?
+	ORDER BY id
+	LIMIT 1;
+	`
+
+	getBookByISBNQuery = `-- get book by isbn
+	SELECT
+		id,
+		isbn,
+		title,
+		original_release,
+		description,
+		short_description,
+		published,
+		publisher_id,
+		pages,
+		seller_id,
+		price,
+		status,
+		version,
+		updated_at
+	FROM books
+	WHERE isbn = ?;
+	`
+
+	getPublisherByNameQuery = `-- get publisher by name
+	SELECT
+		id,
+		name,
+		description,
+		slogan,
+		founded,
+		version,
+		updated_at
+	FROM publishers
+	WHERE name = ?
+	ORDER BY id
+	LIMIT 1;
+	`
+)
+
+func (r booksRepository) GetAuthorByName(ctx context.Context, firstName string, lastName string) (*domain.Author, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var author domain.Author
+	if err := r.db.QueryRowContext(ctx, getAuthorByNameQuery, firstName, lastName).Scan(
+		&author.ID,
+		&author.FirstName,
+		&author.LastName,
+		&author.Biography,
+		&author.Birthday,
+		&author.Death,
+		&author.Version,
+		&author.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, rest_errors.NewNotFoundError("author not found")
+		}
+		return nil, dbError(ctx, err)
+	}
+	return &author, nil
+}
+
+func (r booksRepository) GetPublisherByName(ctx context.Context, name string) (*domain.Publisher, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var publisher domain.Publisher
+	if err := r.db.QueryRowContext(ctx, getPublisherByNameQuery, name).Scan(
+		&publisher.ID,
+		&publisher.Name,
+		&publisher.Description,
+		&publisher.Slogan,
+		&publisher.Founded,
+		&publisher.Version,
+		&publisher.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, rest_errors.NewNotFoundError("publisher not found")
+		}
+		return nil, dbError(ctx, err)
+	}
+	return &publisher, nil
+}
+
+func (r booksRepository) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var book domain.Book
+	if err := r.db.QueryRowContext(ctx, getBookByISBNQuery, isbn).Scan(
+		&book.ID,
+		&book.ISBN,
+		&book.Title,
+		&book.OriginalRelease,
+		&book.Description,
+		&book.ShortDescription,
+		&book.Published,
+		&book.PublisherID,
+		&book.Pages,
+		&book.SellerID,
+		&book.Price,
+		&book.Status,
+		&book.Version,
+		&book.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, rest_errors.NewNotFoundError("book not found")
+		}
+		return nil, dbError(ctx, err)
+	}
+	return &book, nil
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuthorByName(t *testing.T) {
+	query := regexp.QuoteMeta(getAuthorByNameQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows([]string{
+			"id",
+			"first_name",
+			"last_name",
+			"biography",
+			"birthday",
+			"death",
+			"version",
+			"updated_at",
+		}).AddRow(3, "Jorge Luis", "Borges", "", "1899-08-24", "1986-06-14", 1, "2021-12-20 10:00:00")
+		mock.ExpectQuery(query).WithArgs("Jorge Luis", "Borges").WillReturnRows(rows)
+
+		author, err := repo.GetAuthorByName(context.Background(), "Jorge Luis", "Borges")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 3, author.ID)
+		assert.EqualValues(t, "1986-06-14", *author.Death)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(query).WithArgs("Jorge Luis", "Borges").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := repo.GetAuthorByName(context.Background(), "Jorge Luis", "Borges")
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusNotFound, err.Status())
+	})
+	t.Run("TimedOut", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db, timeouts: config.QueryTimeouts{Read: 10 * time.Millisecond}}
+
+		mock.ExpectQuery(query).WithArgs("Jorge Luis", "Borges").WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := repo.GetAuthorByName(context.Background(), "Jorge Luis", "Borges")
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusGatewayTimeout, err.Status())
+	})
+
+	t.Run("CallerGone", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		mock.ExpectQuery(query).WithArgs("Jorge Luis", "Borges").WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := repo.GetAuthorByName(ctx, "Jorge Luis", "Borges")
+		assert.NotNil(t, err)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+const getPendingBooksQuery = `-- get pending books
+	SELECT
+		books.id,
+		books.title,
+		books.short_description,
+		books.published,
+		books.pages,
+		books.price,
+		books.seller_id,
+		books.status_changed_at,
+		publishers.id,
+		publishers.name
+	FROM books
+	INNER JOIN publishers
+		ON publishers.id = books.publisher_id
+	WHERE books.status = 'pending_review'
+	ORDER BY books.status_changed_at, books.id;
+	`
+
+func (r booksRepository) GetPendingBooks(ctx context.Context) ([]domain.Submission, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	stmt, err := r.db.PrepareContext(ctx, getPendingBooksQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	submissions := make([]domain.Submission, 0)
+	for rows.Next() {
+		var submission domain.Submission
+		if err := rows.Scan(
+			&submission.Book.ID,
+			&submission.Book.Title,
+			&submission.Book.ShortDescription,
+			&submission.Book.Published,
+			&submission.Book.Pages,
+			&submission.Book.Price,
+			&submission.SubmittedBy,
+			&submission.SubmittedAt,
+			&submission.Publisher.ID,
+			&submission.Publisher.Name,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		submission.Book.SellerID = submission.SubmittedBy
+		submission.Book.Status = domain.StatusPendingReview
+
+		submissions = append(submissions, submission)
+	}
+
+	return submissions, nil
+}
+
+const saveModerationQuery = `-- save moderation
+	INSERT INTO moderations(
+		book_id,
+		moderator_id,
+		decision,
+		reason,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, UTC_TIMESTAMP()
+	);
+	`
+
+// ModerateBook applies the decision to a pending book and records it, both
+// within the same transaction
+func (r booksRepository) ModerateBook(ctx context.Context, moderation *domain.Moderation) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	if err := updateBookStatus(
+		ctx,
+		tx,
+		moderateBookStatusQuery,
+		moderation.ModeratorID,
+		moderation.BookID,
+		domain.StatusPendingReview,
+		moderation.TargetStatus(),
+	); err != nil {
+		if err.Status() == http.StatusPreconditionFailed {
+			return rest_errors.NewNotFoundError("no pending book with the given id")
+		}
+		return err
+	}
+
+	moderationStmt, err := tx.PrepareContext(ctx, saveModerationQuery)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer moderationStmt.Close()
+
+	inserResult, err := moderationStmt.ExecContext(ctx,
+		moderation.BookID,
+		moderation.ModeratorID,
+		moderation.Decision,
+		moderation.Reason,
+	)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	moderationId, _ := inserResult.LastInsertId()
+	moderation.ID = moderationId
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+const getModerationsQuery = `-- get moderations
+	SELECT
+		id,
+		moderator_id,
+		decision,
+		reason,
+		created_at
+	FROM moderations
+	WHERE book_id = ?
+	ORDER BY created_at, id;
+	`
+
+func (r booksRepository) GetModerations(ctx context.Context, bookID int64) ([]domain.Moderation, rest_errors.RestErr) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	stmt, err := r.db.PrepareContext(ctx, getModerationsQuery)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, bookID)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+	defer rows.Close()
+
+	moderations := make([]domain.Moderation, 0)
+	moderation := domain.Moderation{BookID: bookID}
+	for rows.Next() {
+		if err := rows.Scan(
+			&moderation.ID,
+			&moderation.ModeratorID,
+			&moderation.Decision,
+			&moderation.Reason,
+			&moderation.CreatedAt,
+		); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		moderations = append(moderations, moderation)
+	}
+
+	return moderations, nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPendingBooks(t *testing.T) {
+	query := regexp.QuoteMeta(getPendingBooksQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		rows := sqlmock.NewRows([]string{
+			"books.id",
+			"books.title",
+			"books.short_description",
+			"books.published",
+			"books.pages",
+			"books.price",
+			"books.seller_id",
+			"books.status_changed_at",
+			"publishers.id",
+			"publishers.name",
+		}).AddRow(
+			3,
+			testBook.Title,
+			testBook.ShortDescription,
+			testBook.Published,
+			testBook.Pages,
+			testBook.Price,
+			testBook.SellerID,
+			"2021-12-24 10:00:00",
+			testBook.PublisherID,
+			"penguin",
+		)
+
+		mock.ExpectPrepare(query).ExpectQuery().WillReturnRows(rows)
+
+		submissions, err := repo.GetPendingBooks(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, submissions, 1)
+		assert.EqualValues(t, testBook.SellerID, submissions[0].SubmittedBy)
+		assert.EqualValues(t, domain.StatusPendingReview, submissions[0].Book.Status)
+	})
+}
+
+func TestModerateBook(t *testing.T) {
+	queryStatus := regexp.QuoteMeta(moderateBookStatusQuery)
+	queryModeration := regexp.QuoteMeta(saveModerationQuery)
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		moderation := domain.Moderation{
+			BookID:      3,
+			ModeratorID: 1,
+			Decision:    domain.DecisionRejected,
+			Reason:      "missing cover",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(queryStatus).ExpectExec().WithArgs(
+			domain.StatusDraft,
+			moderation.BookID,
+			domain.StatusPendingReview,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectAudit(mock, moderation.ModeratorID, "update", "book", moderation.BookID)
+		mock.ExpectPrepare(queryModeration).ExpectExec().WithArgs(
+			moderation.BookID,
+			moderation.ModeratorID,
+			moderation.Decision,
+			moderation.Reason,
+		).WillReturnResult(sqlmock.NewResult(5, 1))
+		mock.ExpectCommit()
+
+		err := repo.ModerateBook(context.Background(), &moderation)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 5, moderation.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NotPending", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		moderation := domain.Moderation{BookID: 3, ModeratorID: 1, Decision: domain.DecisionApproved}
+
+		mock.ExpectBegin()
+		mock.ExpectPrepare(queryStatus).ExpectExec().WithArgs(
+			domain.StatusPublished,
+			moderation.BookID,
+			domain.StatusPendingReview,
+		).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.ModerateBook(context.Background(), &moderation)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusNotFound, err.Status())
+	})
+}
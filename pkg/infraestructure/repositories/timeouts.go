@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/logging"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// withTimeout bounds an operation by timeout on top of ctx, which already
+// ends when the caller goes away. A timeout of 0 leaves it bound by ctx only.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// dbError maps a failed database call. Operations that ran out of time answer
+// 504 rather than 500, as they may well go through once the database is less
+// busy. ctx is checked too since drivers don't all report why a query was
+// interrupted.
+func dbError(ctx context.Context, err error) rest_errors.RestErr {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded:
+		logging.FromContext(ctx).Warn().Err(err).Msg("query timed out")
+		return rest_errors.NewRestError(
+			"the database took too long to answer",
+			http.StatusGatewayTimeout,
+			"gateway_timeout",
+			nil,
+		)
+	case errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled:
+		logging.FromContext(ctx).Debug().Msg("query canceled, the caller went away")
+	}
+	return rest_errors.NewInternalServerError(err.Error())
+}
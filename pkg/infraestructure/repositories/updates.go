@@ -0,0 +1,336 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+)
+
+// Updates are optimistic, the caller sends the version it last read and the
+// update only goes through if nobody changed the record since then
+
+func versionConflict() rest_errors.RestErr {
+	return rest_errors.NewRestError(
+		"the record was modified since it was last read, fetch it again",
+		http.StatusPreconditionFailed,
+		"precondition_failed",
+		nil,
+	)
+}
+
+const (
+	getAuthorForUpdateQuery = `-- get author for update
+	SELECT
+		first_name,
+		last_name,
+		biography,
+		birthday,
+		death,
+		version,
+		updated_at
+	FROM authors
+	WHERE id = ?
+	FOR UPDATE;
+	`
+
+	updateAuthorQuery = `-- update author
+	UPDATE authors
+	SET
+		first_name = ?,
+		last_name = ?,
+		biography = ?,
+		birthday = ?,
+		death = ?,
+		version = version + 1,
+		updated_at = UTC_TIMESTAMP()
+	WHERE id = ?;
+	`
+
+	getAuthorUpdatedAtQuery = `-- get author updated at
+	SELECT updated_at FROM authors WHERE id = ?;
+	`
+)
+
+func (r booksRepository) UpdateAuthor(ctx context.Context, actorID int64, author *domain.Author) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	before := domain.Author{ID: author.ID}
+	if err := tx.QueryRowContext(ctx, getAuthorForUpdateQuery, author.ID).Scan(
+		&before.FirstName,
+		&before.LastName,
+		&before.Biography,
+		&before.Birthday,
+		&before.Death,
+		&before.Version,
+		&before.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return rest_errors.NewNotFoundError("author not found")
+		}
+		return dbError(ctx, err)
+	}
+	if before.Version != author.Version {
+		return versionConflict()
+	}
+
+	author.Version++
+
+	if _, err := tx.ExecContext(ctx, updateAuthorQuery,
+		author.FirstName,
+		author.LastName,
+		author.Biography,
+		author.Birthday,
+		author.Death,
+		author.ID,
+	); err != nil {
+		return dbError(ctx, err)
+	}
+	if err := tx.QueryRowContext(ctx, getAuthorUpdatedAtQuery, author.ID).Scan(&author.UpdatedAt); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := saveAudit(ctx, tx, actorID, domain.ActionUpdate, domain.EntityAuthor, author.ID, before, author); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+const (
+	getPublisherForUpdateQuery = `-- get publisher for update
+	SELECT
+		name,
+		description,
+		slogan,
+		founded,
+		version,
+		updated_at
+	FROM publishers
+	WHERE id = ?
+	FOR UPDATE;
+	`
+
+	updatePublisherQuery = `-- update publisher
+	UPDATE publishers
+	SET
+		name = ?,
+		description = ?,
+		slogan = ?,
+		founded = ?,
+		version = version + 1,
+		updated_at = UTC_TIMESTAMP()
+	WHERE id = ?;
+	`
+
+	getPublisherUpdatedAtQuery = `-- get publisher updated at
+	SELECT updated_at FROM publishers WHERE id = ?;
+	`
+)
+
+func (r booksRepository) UpdatePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	before := domain.Publisher{ID: publisher.ID}
+	if err := tx.QueryRowContext(ctx, getPublisherForUpdateQuery, publisher.ID).Scan(
+		&before.Name,
+		&before.Description,
+		&before.Slogan,
+		&before.Founded,
+		&before.Version,
+		&before.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return rest_errors.NewNotFoundError("publisher not found")
+		}
+		return dbError(ctx, err)
+	}
+	if before.Version != publisher.Version {
+		return versionConflict()
+	}
+
+	publisher.Version++
+
+	if _, err := tx.ExecContext(ctx, updatePublisherQuery,
+		publisher.Name,
+		publisher.Description,
+		publisher.Slogan,
+		publisher.Founded,
+		publisher.ID,
+	); err != nil {
+		return dbError(ctx, err)
+	}
+	if err := tx.QueryRowContext(ctx, getPublisherUpdatedAtQuery, publisher.ID).Scan(&publisher.UpdatedAt); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := saveAudit(ctx, tx, actorID, domain.ActionUpdate, domain.EntityPublisher, publisher.ID, before, publisher); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
+
+const (
+	getBookForUpdateQuery = `-- get book for update
+	SELECT
+		COALESCE(isbn, ''),
+		title,
+		original_release,
+		description,
+		short_description,
+		published,
+		publisher_id,
+		pages,
+		seller_id,
+		price,
+		status,
+		version,
+		updated_at
+	FROM books
+	WHERE id = ?
+	FOR UPDATE;
+	`
+
+	updateBookQuery = `-- update book
+	UPDATE books
+	SET
+		isbn = NULLIF(?, ''),
+		title = ?,
+		original_release = ?,
+		description = ?,
+		short_description = ?,
+		published = ?,
+		publisher_id = ?,
+		pages = ?,
+		status_changed_at = IF(status = ?, status_changed_at, UTC_TIMESTAMP()),
+		status = ?,
+		version = version + 1,
+		updated_at = UTC_TIMESTAMP()
+	WHERE id = ?;
+	`
+
+	getBookUpdatedAtQuery = `-- get book updated at
+	SELECT updated_at FROM books WHERE id = ?;
+	`
+
+	deleteAuthorshipQuery = `-- delete authorship
+	DELETE FROM authorship
+	WHERE book_id = ?;
+	`
+)
+
+// UpdateBook changes the descriptive fields of a book and, when AuthorID is
+// set, its authors. Price and status have their own lifecycle and are kept,
+// as is the ISBN when book has none; the only status change book can carry is
+// a public book going back to review, as it does when its seller edits it.
+func (r booksRepository) UpdateBook(ctx context.Context, actorID int64, book *domain.Book) rest_errors.RestErr {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	before := domain.Book{ID: book.ID}
+	if err := tx.QueryRowContext(ctx, getBookForUpdateQuery, book.ID).Scan(
+		&before.ISBN,
+		&before.Title,
+		&before.OriginalRelease,
+		&before.Description,
+		&before.ShortDescription,
+		&before.Published,
+		&before.PublisherID,
+		&before.Pages,
+		&before.SellerID,
+		&before.Price,
+		&before.Status,
+		&before.Version,
+		&before.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return rest_errors.NewNotFoundError("book not found")
+		}
+		return dbError(ctx, err)
+	}
+	if before.Version != book.Version {
+		return versionConflict()
+	}
+
+	if book.ISBN == "" {
+		book.ISBN = before.ISBN
+	}
+	book.SellerID = before.SellerID
+	book.Price = before.Price
+	if book.Status != domain.StatusPendingReview || !domain.IsPublicStatus(before.Status) {
+		book.Status = before.Status
+	}
+	book.Version++
+
+	if _, err := tx.ExecContext(ctx, updateBookQuery,
+		book.ISBN,
+		book.Title,
+		book.OriginalRelease,
+		book.Description,
+		book.ShortDescription,
+		book.Published,
+		book.PublisherID,
+		book.Pages,
+		book.Status,
+		book.Status,
+		book.ID,
+	); err != nil {
+		return saveBookError(ctx, err)
+	}
+	if err := tx.QueryRowContext(ctx, getBookUpdatedAtQuery, book.ID).Scan(&book.UpdatedAt); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if book.AuthorID != nil {
+		if _, err := tx.ExecContext(ctx, deleteAuthorshipQuery, book.ID); err != nil {
+			return dbError(ctx, err)
+		}
+
+		for _, authorID := range book.AuthorID {
+			if _, err := tx.ExecContext(ctx, saveAuthorshipQuery, book.ID, authorID); err != nil {
+				return dbError(ctx, err)
+			}
+			if _, err := tx.ExecContext(ctx, savePublishedQuery, authorID, book.PublisherID); err != nil {
+				return dbError(ctx, err)
+			}
+		}
+	}
+
+	if err := saveAudit(ctx, tx, actorID, domain.ActionUpdate, domain.EntityBook, book.ID, before, book); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+	return nil
+}
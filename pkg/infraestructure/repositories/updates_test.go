@@ -0,0 +1,282 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAuthor(t *testing.T) {
+	queryCurrent := regexp.QuoteMeta(getAuthorForUpdateQuery)
+	queryUpdate := regexp.QuoteMeta(updateAuthorQuery)
+
+	currentRow := func(version int64) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{
+			"first_name",
+			"last_name",
+			"biography",
+			"birthday",
+			"death",
+			"version",
+			"updated_at",
+		}).AddRow("Philip", "Dick", "", "16-12-1928", nil, version, "2021-12-20 10:00:00")
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		author := domain.Author{ID: 4, FirstName: "Philip K.", LastName: "Dick", Birthday: "16-12-1928", Version: 2}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(queryCurrent).WithArgs(4).WillReturnRows(currentRow(2))
+		mock.ExpectExec(queryUpdate).WithArgs(
+			author.FirstName,
+			author.LastName,
+			author.Biography,
+			author.Birthday,
+			author.Death,
+			author.ID,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(getAuthorUpdatedAtQuery)).WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow("2021-12-21 09:30:00"))
+		expectAudit(mock, 1, "update", "author", 4)
+		mock.ExpectCommit()
+
+		err := repo.UpdateAuthor(context.Background(), 1, &author)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 3, author.Version)
+		assert.EqualValues(t, "2021-12-21 09:30:00", author.UpdatedAt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Modified", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		author := domain.Author{ID: 4, FirstName: "Philip K.", Version: 2}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(queryCurrent).WithArgs(4).WillReturnRows(currentRow(3))
+		mock.ExpectRollback()
+
+		err := repo.UpdateAuthor(context.Background(), 1, &author)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusPreconditionFailed, err.Status())
+		assert.EqualValues(t, 2, author.Version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		author := domain.Author{ID: 4, Version: 2}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(queryCurrent).WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"first_name"}))
+		mock.ExpectRollback()
+
+		err := repo.UpdateAuthor(context.Background(), 1, &author)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusNotFound, err.Status())
+	})
+}
+
+func TestUpdateBook(t *testing.T) {
+	currentRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{
+			"isbn",
+			"title",
+			"original_release",
+			"description",
+			"short_description",
+			"published",
+			"publisher_id",
+			"pages",
+			"seller_id",
+			"price",
+			"status",
+			"version",
+			"updated_at",
+		}).AddRow("9780802130303", "Ficciones", "1944-01-01", "", "", "1962-01-01", 2, 174, 7, 1999, "published", 3, "2021-12-20 10:00:00")
+	}
+
+	t.Run("NoError", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		book := domain.Book{
+			ID:              1,
+			ISBN:            "9780802130303",
+			Title:           "Ficciones",
+			OriginalRelease: "1944-01-01",
+			Published:       "1962-01-01",
+			PublisherID:     2,
+			Pages:           180,
+			AuthorID:        []int64{3},
+			Version:         3,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(getBookForUpdateQuery)).WithArgs(1).WillReturnRows(currentRow())
+		mock.ExpectExec(regexp.QuoteMeta(updateBookQuery)).WithArgs(
+			book.ISBN,
+			book.Title,
+			book.OriginalRelease,
+			book.Description,
+			book.ShortDescription,
+			book.Published,
+			book.PublisherID,
+			book.Pages,
+			domain.StatusPublished,
+			domain.StatusPublished,
+			book.ID,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(getBookUpdatedAtQuery)).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow("2021-12-21 09:30:00"))
+		mock.ExpectExec(regexp.QuoteMeta(deleteAuthorshipQuery)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(saveAuthorshipQuery)).WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(savePublishedQuery)).WithArgs(3, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectAudit(mock, 7, "update", "book", 1)
+		mock.ExpectCommit()
+
+		err := repo.UpdateBook(context.Background(), 7, &book)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 4, book.Version)
+		assert.EqualValues(t, "2021-12-21 09:30:00", book.UpdatedAt)
+		assert.EqualValues(t, domain.StatusPublished, book.Status)
+		assert.EqualValues(t, 1999, book.Price)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("KeepsISBN", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		book := domain.Book{
+			ID:              1,
+			Title:           "Ficciones",
+			OriginalRelease: "1944-01-01",
+			Published:       "1962-01-01",
+			PublisherID:     2,
+			Pages:           180,
+			AuthorID:        []int64{3},
+			Version:         3,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(getBookForUpdateQuery)).WithArgs(1).WillReturnRows(currentRow())
+		mock.ExpectExec(regexp.QuoteMeta(updateBookQuery)).WithArgs(
+			"9780802130303",
+			book.Title,
+			book.OriginalRelease,
+			book.Description,
+			book.ShortDescription,
+			book.Published,
+			book.PublisherID,
+			book.Pages,
+			domain.StatusPublished,
+			domain.StatusPublished,
+			book.ID,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(getBookUpdatedAtQuery)).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow("2021-12-21 09:30:00"))
+		mock.ExpectExec(regexp.QuoteMeta(deleteAuthorshipQuery)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(saveAuthorshipQuery)).WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(savePublishedQuery)).WithArgs(3, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectAudit(mock, 7, "update", "book", 1)
+		mock.ExpectCommit()
+
+		err := repo.UpdateBook(context.Background(), 7, &book)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 4, book.Version)
+		assert.EqualValues(t, "2021-12-21 09:30:00", book.UpdatedAt)
+		assert.EqualValues(t, domain.StatusPublished, book.Status)
+		assert.EqualValues(t, 1999, book.Price)
+		assert.Nil(t, mock.ExpectationsWereMet())
+		assert.EqualValues(t, "9780802130303", book.ISBN)
+	})
+
+	t.Run("BackToReview", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		book := domain.Book{
+			ID:              1,
+			Title:           "Ficciones",
+			OriginalRelease: "1944-01-01",
+			Published:       "1962-01-01",
+			PublisherID:     2,
+			Pages:           180,
+			Status:          domain.StatusPendingReview,
+			Version:         3,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(getBookForUpdateQuery)).WithArgs(1).WillReturnRows(currentRow())
+		mock.ExpectExec(regexp.QuoteMeta(updateBookQuery)).WithArgs(
+			"9780802130303",
+			book.Title,
+			book.OriginalRelease,
+			book.Description,
+			book.ShortDescription,
+			book.Published,
+			book.PublisherID,
+			book.Pages,
+			domain.StatusPendingReview,
+			domain.StatusPendingReview,
+			book.ID,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(getBookUpdatedAtQuery)).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow("2021-12-21 09:30:00"))
+		expectAudit(mock, 7, "update", "book", 1)
+		mock.ExpectCommit()
+
+		err := repo.UpdateBook(context.Background(), 7, &book)
+		assert.Nil(t, err)
+		assert.EqualValues(t, domain.StatusPendingReview, book.Status)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NoOtherStatusChange", func(t *testing.T) {
+		db, mock := NewMock()
+		repo := booksRepository{db: db}
+
+		book := domain.Book{
+			ID:              1,
+			Title:           "Ficciones",
+			OriginalRelease: "1944-01-01",
+			Published:       "1962-01-01",
+			PublisherID:     2,
+			Pages:           180,
+			Status:          domain.StatusWithdrawn,
+			Version:         3,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(getBookForUpdateQuery)).WithArgs(1).WillReturnRows(currentRow())
+		mock.ExpectExec(regexp.QuoteMeta(updateBookQuery)).WithArgs(
+			"9780802130303",
+			book.Title,
+			book.OriginalRelease,
+			book.Description,
+			book.ShortDescription,
+			book.Published,
+			book.PublisherID,
+			book.Pages,
+			domain.StatusPublished,
+			domain.StatusPublished,
+			book.ID,
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(getBookUpdatedAtQuery)).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow("2021-12-21 09:30:00"))
+		expectAudit(mock, 7, "update", "book", 1)
+		mock.ExpectCommit()
+
+		err := repo.UpdateBook(context.Background(), 7, &book)
+		assert.Nil(t, err)
+		assert.EqualValues(t, domain.StatusPublished, book.Status)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/tracing"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// validateFunc returns the user token belongs to, failing when the token
+// isn't valid or the users service couldn't tell
+type validateFunc func(ctx context.Context, token string) (auth.UserPayload, error)
+
+type authenticator struct {
+	validate validateFunc
+}
+
+func newAuthenticator(oc *auth.Client) *authenticator {
+	return &authenticator{validate: usersService(oc)}
+}
+
+// usersService validates tokens with the users service oc is connected to.
+// bookstore_utils-go only exposes the validation as auth.RequiresAuth, which
+// is called straight on a bare gin.Context, so both APIs share one set of
+// auth rules without routing every RPC through a gin engine.
+func usersService(oc *auth.Client) validateFunc {
+	check := tracing.Auth(func(*gin.Context) {}, func(next gin.HandlerFunc) gin.HandlerFunc {
+		return auth.RequiresAuth(next, oc.C)
+	})
+
+	return func(ctx context.Context, token string) (auth.UserPayload, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+		if err != nil {
+			return auth.UserPayload{}, err
+		}
+		req.Header.Set("Authorization", token)
+
+		writer := &statusWriter{header: http.Header{}, status: http.StatusOK}
+		c := &gin.Context{Request: req, Writer: writer}
+		check(c)
+
+		payload, ok := c.Get("user_payload")
+		if !ok || c.IsAborted() {
+			return auth.UserPayload{}, fmt.Errorf("users service turned the token down with status %d", writer.status)
+		}
+		return payload.(auth.UserPayload), nil
+	}
+}
+
+func hasToken(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return len(md.Get("authorization")) > 0
+}
+
+func (a *authenticator) authenticate(ctx context.Context) (auth.UserPayload, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get("authorization")
+	if len(tokens) == 0 {
+		return auth.UserPayload{}, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	payload, err := a.validate(ctx, tokens[0])
+	if err != nil {
+		return payload, status.Error(codes.Unauthenticated, "invalid access token")
+	}
+	return payload, nil
+}
+
+func (a *authenticator) authenticateAdmin(ctx context.Context) (auth.UserPayload, error) {
+	payload, err := a.authenticate(ctx)
+	if err != nil {
+		return payload, err
+	}
+
+	if payload.Role != "admin" {
+		return payload, status.Error(codes.PermissionDenied, "you don't have the permissions to access this resource")
+	}
+	return payload, nil
+}
+
+// statusWriter is the gin.ResponseWriter auth.RequiresAuth writes to, it keeps
+// the status the token was turned down with and drops the body
+type statusWriter struct {
+	header  http.Header
+	status  int
+	size    int
+	written bool
+}
+
+func (w *statusWriter) Header() http.Header { return w.header }
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+	}
+}
+
+func (w *statusWriter) WriteHeaderNow() { w.written = true }
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	w.written = true
+	w.size += len(data)
+	return len(data), nil
+}
+
+func (w *statusWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *statusWriter) Status() int   { return w.status }
+func (w *statusWriter) Size() int     { return w.size }
+func (w *statusWriter) Written() bool { return w.written }
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("a token check has no connection to hijack")
+}
+
+func (w *statusWriter) Flush()                   {}
+func (w *statusWriter) CloseNotify() <-chan bool { return nil }
+func (w *statusWriter) Pusher() http.Pusher      { return nil }
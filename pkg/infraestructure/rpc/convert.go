@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/rpc/pb"
+)
+
+func toPbAuthor(a domain.Author) *pb.Author {
+	return &pb.Author{
+		Id:        a.ID,
+		FirstName: a.FirstName,
+		LastName:  a.LastName,
+		Biography: a.Biography,
+		Birthday:  a.Birthday,
+		Death:     a.Death,
+	}
+}
+
+func fromPbAuthor(a *pb.Author) domain.Author {
+	return domain.Author{
+		ID:        a.GetId(),
+		FirstName: a.GetFirstName(),
+		LastName:  a.GetLastName(),
+		Biography: a.GetBiography(),
+		Birthday:  a.GetBirthday(),
+		Death:     a.Death,
+	}
+}
+
+func toPbAuthors(authors []domain.Author) []*pb.Author {
+	out := make([]*pb.Author, len(authors))
+	for i := range authors {
+		out[i] = toPbAuthor(authors[i])
+	}
+	return out
+}
+
+func toPbPublisher(p domain.Publisher) *pb.Publisher {
+	return &pb.Publisher{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Slogan:      p.Slogan,
+		Founded:     p.Founded,
+	}
+}
+
+func fromPbPublisher(p *pb.Publisher) domain.Publisher {
+	return domain.Publisher{
+		ID:          p.GetId(),
+		Name:        p.GetName(),
+		Description: p.GetDescription(),
+		Slogan:      p.GetSlogan(),
+		Founded:     p.GetFounded(),
+	}
+}
+
+func toPbPublishers(publishers []domain.Publisher) []*pb.Publisher {
+	out := make([]*pb.Publisher, len(publishers))
+	for i := range publishers {
+		out[i] = toPbPublisher(publishers[i])
+	}
+	return out
+}
+
+func toPbBook(b domain.Book) *pb.Book {
+	return &pb.Book{
+		Id:               b.ID,
+		Title:            b.Title,
+		OriginalRelease:  b.OriginalRelease,
+		Description:      b.Description,
+		ShortDescription: b.ShortDescription,
+		Published:        b.Published,
+		PublisherId:      b.PublisherID,
+		Pages:            b.Pages,
+		AuthorId:         b.AuthorID,
+		SellerId:         b.SellerID,
+		Price:            b.Price,
+		Status:           b.Status,
+		Isbn:             b.ISBN,
+	}
+}
+
+// fromPbBook fails when b carries an ISBN that isn't valid, the one returned
+// is normalized as over REST
+func fromPbBook(b *pb.Book) (domain.Book, error) {
+	book := domain.Book{
+		ID:               b.GetId(),
+		ISBN:             b.GetIsbn(),
+		Title:            b.GetTitle(),
+		OriginalRelease:  b.GetOriginalRelease(),
+		Description:      b.GetDescription(),
+		ShortDescription: b.GetShortDescription(),
+		Published:        b.GetPublished(),
+		PublisherID:      b.GetPublisherId(),
+		Pages:            b.GetPages(),
+		AuthorID:         b.GetAuthorId(),
+		SellerID:         b.GetSellerId(),
+		Price:            b.GetPrice(),
+		Status:           b.GetStatus(),
+	}
+	if book.ISBN != "" {
+		isbn, err := domain.NormalizeISBN(book.ISBN)
+		if err != nil {
+			return book, err
+		}
+		book.ISBN = isbn
+	}
+	return book, nil
+}
+
+func toPbBooks(books []domain.Book) []*pb.Book {
+	out := make([]*pb.Book, len(books))
+	for i := range books {
+		out[i] = toPbBook(books[i])
+	}
+	return out
+}
+
+func toPbPromotions(promotions []domain.Promotion) []*pb.Promotion {
+	out := make([]*pb.Promotion, len(promotions))
+	for i, p := range promotions {
+		out[i] = &pb.Promotion{
+			Id:       p.ID,
+			Kind:     p.Kind,
+			Amount:   p.Amount,
+			StartsAt: p.StartsAt,
+			EndsAt:   p.EndsAt,
+		}
+	}
+	return out
+}
+
+func fromPbListRequest(r *pb.ListRequest) domain.ListOptions {
+	return domain.ListOptions{
+		AfterID: r.GetAfterId(),
+		Limit:   int(r.GetLimit()),
+	}.Normalize()
+}
@@ -0,0 +1,389 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// BooksServiceClient is the client API for BooksService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BooksServiceClient interface {
+	GetAuthor(ctx context.Context, in *GetByIdRequest, opts ...grpc.CallOption) (*AuthorDenormalized, error)
+	ListAuthors(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error)
+	CreateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*Author, error)
+	GetPublisher(ctx context.Context, in *GetByIdRequest, opts ...grpc.CallOption) (*PublisherDenormalized, error)
+	ListPublishers(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListPublishersResponse, error)
+	CreatePublisher(ctx context.Context, in *Publisher, opts ...grpc.CallOption) (*Publisher, error)
+	GetBook(ctx context.Context, in *GetByIdRequest, opts ...grpc.CallOption) (*BookDenormalized, error)
+	ListBooks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListBooksResponse, error)
+	CreateBook(ctx context.Context, in *Book, opts ...grpc.CallOption) (*Book, error)
+}
+
+type booksServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBooksServiceClient(cc grpc.ClientConnInterface) BooksServiceClient {
+	return &booksServiceClient{cc}
+}
+
+func (c *booksServiceClient) GetAuthor(ctx context.Context, in *GetByIdRequest, opts ...grpc.CallOption) (*AuthorDenormalized, error) {
+	out := new(AuthorDenormalized)
+	err := c.cc.Invoke(ctx, "/books.BooksService/GetAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) ListAuthors(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error) {
+	out := new(ListAuthorsResponse)
+	err := c.cc.Invoke(ctx, "/books.BooksService/ListAuthors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) CreateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, "/books.BooksService/CreateAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) GetPublisher(ctx context.Context, in *GetByIdRequest, opts ...grpc.CallOption) (*PublisherDenormalized, error) {
+	out := new(PublisherDenormalized)
+	err := c.cc.Invoke(ctx, "/books.BooksService/GetPublisher", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) ListPublishers(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListPublishersResponse, error) {
+	out := new(ListPublishersResponse)
+	err := c.cc.Invoke(ctx, "/books.BooksService/ListPublishers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) CreatePublisher(ctx context.Context, in *Publisher, opts ...grpc.CallOption) (*Publisher, error) {
+	out := new(Publisher)
+	err := c.cc.Invoke(ctx, "/books.BooksService/CreatePublisher", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) GetBook(ctx context.Context, in *GetByIdRequest, opts ...grpc.CallOption) (*BookDenormalized, error) {
+	out := new(BookDenormalized)
+	err := c.cc.Invoke(ctx, "/books.BooksService/GetBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) ListBooks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListBooksResponse, error) {
+	out := new(ListBooksResponse)
+	err := c.cc.Invoke(ctx, "/books.BooksService/ListBooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *booksServiceClient) CreateBook(ctx context.Context, in *Book, opts ...grpc.CallOption) (*Book, error) {
+	out := new(Book)
+	err := c.cc.Invoke(ctx, "/books.BooksService/CreateBook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BooksServiceServer is the server API for BooksService service.
+// All implementations must embed UnimplementedBooksServiceServer
+// for forward compatibility
+type BooksServiceServer interface {
+	GetAuthor(context.Context, *GetByIdRequest) (*AuthorDenormalized, error)
+	ListAuthors(context.Context, *ListRequest) (*ListAuthorsResponse, error)
+	CreateAuthor(context.Context, *Author) (*Author, error)
+	GetPublisher(context.Context, *GetByIdRequest) (*PublisherDenormalized, error)
+	ListPublishers(context.Context, *ListRequest) (*ListPublishersResponse, error)
+	CreatePublisher(context.Context, *Publisher) (*Publisher, error)
+	GetBook(context.Context, *GetByIdRequest) (*BookDenormalized, error)
+	ListBooks(context.Context, *ListRequest) (*ListBooksResponse, error)
+	CreateBook(context.Context, *Book) (*Book, error)
+	mustEmbedUnimplementedBooksServiceServer()
+}
+
+// UnimplementedBooksServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBooksServiceServer struct {
+}
+
+func (UnimplementedBooksServiceServer) GetAuthor(context.Context, *GetByIdRequest) (*AuthorDenormalized, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthor not implemented")
+}
+func (UnimplementedBooksServiceServer) ListAuthors(context.Context, *ListRequest) (*ListAuthorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuthors not implemented")
+}
+func (UnimplementedBooksServiceServer) CreateAuthor(context.Context, *Author) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAuthor not implemented")
+}
+func (UnimplementedBooksServiceServer) GetPublisher(context.Context, *GetByIdRequest) (*PublisherDenormalized, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublisher not implemented")
+}
+func (UnimplementedBooksServiceServer) ListPublishers(context.Context, *ListRequest) (*ListPublishersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPublishers not implemented")
+}
+func (UnimplementedBooksServiceServer) CreatePublisher(context.Context, *Publisher) (*Publisher, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePublisher not implemented")
+}
+func (UnimplementedBooksServiceServer) GetBook(context.Context, *GetByIdRequest) (*BookDenormalized, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBook not implemented")
+}
+func (UnimplementedBooksServiceServer) ListBooks(context.Context, *ListRequest) (*ListBooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBooks not implemented")
+}
+func (UnimplementedBooksServiceServer) CreateBook(context.Context, *Book) (*Book, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBook not implemented")
+}
+func (UnimplementedBooksServiceServer) mustEmbedUnimplementedBooksServiceServer() {}
+
+// UnsafeBooksServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BooksServiceServer will
+// result in compilation errors.
+type UnsafeBooksServiceServer interface {
+	mustEmbedUnimplementedBooksServiceServer()
+}
+
+func RegisterBooksServiceServer(s grpc.ServiceRegistrar, srv BooksServiceServer) {
+	s.RegisterService(&BooksService_ServiceDesc, srv)
+}
+
+func _BooksService_GetAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).GetAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/GetAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).GetAuthor(ctx, req.(*GetByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_ListAuthors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).ListAuthors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/ListAuthors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).ListAuthors(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_CreateAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Author)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).CreateAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/CreateAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).CreateAuthor(ctx, req.(*Author))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_GetPublisher_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).GetPublisher(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/GetPublisher",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).GetPublisher(ctx, req.(*GetByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_ListPublishers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).ListPublishers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/ListPublishers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).ListPublishers(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_CreatePublisher_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Publisher)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).CreatePublisher(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/CreatePublisher",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).CreatePublisher(ctx, req.(*Publisher))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_GetBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).GetBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/GetBook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).GetBook(ctx, req.(*GetByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_ListBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).ListBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/ListBooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).ListBooks(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BooksService_CreateBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Book)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BooksServiceServer).CreateBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.BooksService/CreateBook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BooksServiceServer).CreateBook(ctx, req.(*Book))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BooksService_ServiceDesc is the grpc.ServiceDesc for BooksService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BooksService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "books.BooksService",
+	HandlerType: (*BooksServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAuthor",
+			Handler:    _BooksService_GetAuthor_Handler,
+		},
+		{
+			MethodName: "ListAuthors",
+			Handler:    _BooksService_ListAuthors_Handler,
+		},
+		{
+			MethodName: "CreateAuthor",
+			Handler:    _BooksService_CreateAuthor_Handler,
+		},
+		{
+			MethodName: "GetPublisher",
+			Handler:    _BooksService_GetPublisher_Handler,
+		},
+		{
+			MethodName: "ListPublishers",
+			Handler:    _BooksService_ListPublishers_Handler,
+		},
+		{
+			MethodName: "CreatePublisher",
+			Handler:    _BooksService_CreatePublisher_Handler,
+		},
+		{
+			MethodName: "GetBook",
+			Handler:    _BooksService_GetBook_Handler,
+		},
+		{
+			MethodName: "ListBooks",
+			Handler:    _BooksService_ListBooks_Handler,
+		},
+		{
+			MethodName: "CreateBook",
+			Handler:    _BooksService_CreateBook_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "books.proto",
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/rpc/pb"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"google.golang.org/grpc"
+)
+
+type Server struct {
+	addr string
+	srv  *grpc.Server
+}
+
+func NewServer(addr string, br ports.BooksRepositoryInterface, oc *auth.Client) *Server {
+	server := &Server{
+		addr: addr,
+		srv:  grpc.NewServer(),
+	}
+
+	pb.RegisterBooksServiceServer(server.srv, &booksService{
+		br:   br,
+		auth: newAuthenticator(oc),
+	})
+
+	return server
+}
+
+// Start serves until the server is stopped, it only returns an error when
+// serving failed
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("error while listening: %w", err)
+	}
+
+	if err := s.srv.Serve(lis); err != nil {
+		return fmt.Errorf("error while serving grpc: %w", err)
+	}
+	return nil
+}
+
+// Stop waits for in-flight calls to finish, cancelling them once ctx is done
+func (s *Server) Stop(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.srv.Stop()
+	}
+}
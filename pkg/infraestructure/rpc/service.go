@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/rpc/pb"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type booksService struct {
+	pb.UnimplementedBooksServiceServer
+
+	br   ports.BooksRepositoryInterface
+	auth *authenticator
+}
+
+// toStatus translates repository errors into their gRPC counterparts
+func toStatus(err rest_errors.RestErr) error {
+	code := codes.Internal
+	switch err.Status() {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		// the version sent is stale or missing, the call can be retried on
+		// the book as it is now
+		code = codes.FailedPrecondition
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Message())
+}
+
+func (s *booksService) GetAuthor(ctx context.Context, req *pb.GetByIdRequest) (*pb.AuthorDenormalized, error) {
+	author, err := s.br.GetAuthorById(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.AuthorDenormalized{
+		Author: toPbAuthor(author.Author),
+		Books:  toPbBooks(author.Books),
+	}, nil
+}
+
+func (s *booksService) ListAuthors(ctx context.Context, req *pb.ListRequest) (*pb.ListAuthorsResponse, error) {
+	authors, err := s.br.ListAuthors(ctx, fromPbListRequest(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ListAuthorsResponse{Authors: toPbAuthors(authors)}, nil
+}
+
+func (s *booksService) CreateAuthor(ctx context.Context, req *pb.Author) (*pb.Author, error) {
+	user, authErr := s.auth.authenticateAdmin(ctx)
+	if authErr != nil {
+		return nil, authErr
+	}
+
+	author := fromPbAuthor(req)
+	if err := s.br.SaveAuthor(ctx, user.Id, &author); err != nil {
+		return nil, toStatus(err)
+	}
+	return toPbAuthor(author), nil
+}
+
+func (s *booksService) GetPublisher(ctx context.Context, req *pb.GetByIdRequest) (*pb.PublisherDenormalized, error) {
+	publisher, err := s.br.GetPublisherById(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.PublisherDenormalized{
+		Publisher: toPbPublisher(publisher.Publisher),
+		Authors:   toPbAuthors(publisher.Authors),
+		Books:     toPbBooks(publisher.Books),
+	}, nil
+}
+
+func (s *booksService) ListPublishers(ctx context.Context, req *pb.ListRequest) (*pb.ListPublishersResponse, error) {
+	publishers, err := s.br.ListPublishers(ctx, fromPbListRequest(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ListPublishersResponse{Publishers: toPbPublishers(publishers)}, nil
+}
+
+func (s *booksService) CreatePublisher(ctx context.Context, req *pb.Publisher) (*pb.Publisher, error) {
+	user, authErr := s.auth.authenticateAdmin(ctx)
+	if authErr != nil {
+		return nil, authErr
+	}
+
+	publisher := fromPbPublisher(req)
+	if err := s.br.SavePublisher(ctx, user.Id, &publisher); err != nil {
+		return nil, toStatus(err)
+	}
+	return toPbPublisher(publisher), nil
+}
+
+func (s *booksService) GetBook(ctx context.Context, req *pb.GetByIdRequest) (*pb.BookDenormalized, error) {
+	book, err := s.br.GetBookById(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// same as over REST, books that aren't public are only shown to their
+	// seller and to admins
+	if !domain.IsPublicStatus(book.Book.Status) {
+		if !hasToken(ctx) {
+			return nil, status.Error(codes.NotFound, "book not found")
+		}
+		user, authErr := s.auth.authenticate(ctx)
+		if authErr != nil {
+			return nil, authErr
+		}
+		if user.Role != "admin" && user.Id != book.Book.SellerID {
+			return nil, status.Error(codes.NotFound, "book not found")
+		}
+	}
+
+	return &pb.BookDenormalized{
+		Book:           toPbBook(book.Book),
+		Authors:        toPbAuthors(book.Authors),
+		Publisher:      toPbPublisher(book.Publisher),
+		Promotions:     toPbPromotions(book.Promotions),
+		EffectivePrice: book.EffectivePrice,
+	}, nil
+}
+
+func (s *booksService) ListBooks(ctx context.Context, req *pb.ListRequest) (*pb.ListBooksResponse, error) {
+	books, err := s.br.ListBooks(ctx, fromPbListRequest(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ListBooksResponse{Books: toPbBooks(books)}, nil
+}
+
+func (s *booksService) CreateBook(ctx context.Context, req *pb.Book) (*pb.Book, error) {
+	user, authErr := s.auth.authenticateAdmin(ctx)
+	if authErr != nil {
+		return nil, authErr
+	}
+
+	book, err := fromPbBook(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	book.SellerID = user.Id
+	book.Status = domain.StatusPendingReview
+
+	if book.Price < 0 {
+		return nil, status.Error(codes.InvalidArgument, "price can't be negative")
+	}
+
+	if err := s.br.SaveBook(ctx, user.Id, &book); err != nil {
+		return nil, toStatus(err)
+	}
+	return toPbBook(book), nil
+}
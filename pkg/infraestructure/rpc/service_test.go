@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_books-api/pkg/infraestructure/rpc/pb"
+	"github.com/FacuBar/bookstore_utils-go/auth"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeRepo serves the books it holds, the methods a test doesn't use panic
+type fakeRepo struct {
+	ports.BooksRepositoryInterface
+	books     map[int64]domain.Book
+	authorErr rest_errors.RestErr
+	actorID   int64
+	saved     *domain.Book
+}
+
+func (r *fakeRepo) GetBookById(_ context.Context, id int64) (*domain.BookDenormalized, rest_errors.RestErr) {
+	book, ok := r.books[id]
+	if !ok {
+		return nil, rest_errors.NewNotFoundError("book not found")
+	}
+	return &domain.BookDenormalized{Book: book}, nil
+}
+
+func (r *fakeRepo) GetAuthorById(_ context.Context, id int64) (*domain.AuthorDenormalized, rest_errors.RestErr) {
+	if r.authorErr != nil {
+		return nil, r.authorErr
+	}
+	return &domain.AuthorDenormalized{Author: domain.Author{ID: id}}, nil
+}
+
+func (r *fakeRepo) SaveAuthor(_ context.Context, actorID int64, author *domain.Author) rest_errors.RestErr {
+	r.actorID = actorID
+	author.ID = 1
+	return nil
+}
+
+func (r *fakeRepo) SaveBook(_ context.Context, actorID int64, book *domain.Book) rest_errors.RestErr {
+	r.actorID = actorID
+	book.ID = 1
+	r.saved = book
+	return nil
+}
+
+// users are the tokens the fake users service knows of
+var users = map[string]auth.UserPayload{
+	"Bearer seller": {Id: 7, Role: "user"},
+	"Bearer other":  {Id: 8, Role: "user"},
+	"Bearer admin":  {Id: 1, Role: "admin"},
+}
+
+func validateFake(_ context.Context, token string) (auth.UserPayload, error) {
+	user, ok := users[token]
+	if !ok {
+		return user, errors.New("invalid access token")
+	}
+	return user, nil
+}
+
+// dial serves a booksService backed by repo over an in-memory connection
+func dial(t *testing.T, repo ports.BooksRepositoryInterface) pb.BooksServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterBooksServiceServer(srv, &booksService{
+		br:   repo,
+		auth: &authenticator{validate: validateFake},
+	})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewBooksServiceClient(conn)
+}
+
+// as returns a context carrying token as the authorization metadata, none
+// when it's empty
+func as(token string) context.Context {
+	if token == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+}
+
+func TestToStatus(t *testing.T) {
+	cases := []struct {
+		name string
+		err  rest_errors.RestErr
+		code codes.Code
+	}{
+		{"BadRequest", rest_errors.NewBadRequestError("invalid id"), codes.InvalidArgument},
+		{"Unauthorized", rest_errors.NewUnauthorizedError("invalid access token"), codes.Unauthenticated},
+		{"Forbidden", rest_errors.NewRestError("forbidden", http.StatusForbidden, "forbidden", nil), codes.PermissionDenied},
+		{"NotFound", rest_errors.NewNotFoundError("author not found"), codes.NotFound},
+		{"PreconditionFailed", rest_errors.NewRestError("the book was modified", http.StatusPreconditionFailed, "precondition_failed", nil), codes.FailedPrecondition},
+		{"PreconditionRequired", rest_errors.NewRestError("If-Match is required", http.StatusPreconditionRequired, "precondition_required", nil), codes.FailedPrecondition},
+		{"GatewayTimeout", rest_errors.NewRestError("the database took too long", http.StatusGatewayTimeout, "gateway_timeout", nil), codes.DeadlineExceeded},
+		{"Internal", rest_errors.NewInternalServerError("database error"), codes.Internal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := dial(t, &fakeRepo{authorErr: tc.err})
+
+			_, err := client.GetAuthor(context.Background(), &pb.GetByIdRequest{Id: 1})
+			assert.EqualValues(t, tc.code, status.Code(err))
+			assert.EqualValues(t, tc.err.Message(), status.Convert(err).Message())
+		})
+	}
+}
+
+func TestGetBook(t *testing.T) {
+	client := dial(t, &fakeRepo{books: map[int64]domain.Book{
+		1: {ID: 1, SellerID: 7, Status: domain.StatusPublished},
+		2: {ID: 2, SellerID: 7, Status: domain.StatusDraft},
+	}})
+
+	cases := []struct {
+		name  string
+		id    int64
+		token string
+		code  codes.Code
+	}{
+		{"Published", 1, "", codes.OK},
+		{"PublishedWithInvalidToken", 1, "Bearer expired", codes.OK},
+		{"NotFound", 3, "", codes.NotFound},
+		{"DraftToAnonymous", 2, "", codes.NotFound},
+		{"DraftWithInvalidToken", 2, "Bearer expired", codes.Unauthenticated},
+		{"DraftToAnotherUser", 2, "Bearer other", codes.NotFound},
+		{"DraftToSeller", 2, "Bearer seller", codes.OK},
+		{"DraftToAdmin", 2, "Bearer admin", codes.OK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			book, err := client.GetBook(as(tc.token), &pb.GetByIdRequest{Id: tc.id})
+			assert.EqualValues(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.EqualValues(t, tc.id, book.GetBook().GetId())
+			}
+		})
+	}
+}
+
+func TestCreateAuthor(t *testing.T) {
+	cases := []struct {
+		name  string
+		token string
+		code  codes.Code
+	}{
+		{"Admin", "Bearer admin", codes.OK},
+		{"NoToken", "", codes.Unauthenticated},
+		{"InvalidToken", "Bearer expired", codes.Unauthenticated},
+		{"NotAdmin", "Bearer seller", codes.PermissionDenied},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			client := dial(t, repo)
+
+			author, err := client.CreateAuthor(as(tc.token), &pb.Author{FirstName: "Jorge Luis", LastName: "Borges"})
+			assert.EqualValues(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.EqualValues(t, 1, author.GetId())
+				assert.EqualValues(t, 1, repo.actorID)
+			} else {
+				assert.Zero(t, repo.actorID)
+			}
+		})
+	}
+}
+
+func TestGetBookISBN(t *testing.T) {
+	client := dial(t, &fakeRepo{books: map[int64]domain.Book{
+		1: {ID: 1, ISBN: "9780306406157", Status: domain.StatusPublished},
+	}})
+
+	book, err := client.GetBook(context.Background(), &pb.GetByIdRequest{Id: 1})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "9780306406157", book.GetBook().GetIsbn())
+}
+
+func TestCreateBook(t *testing.T) {
+	create := func(isbn string) (*fakeRepo, *pb.Book, error) {
+		repo := &fakeRepo{}
+		book, err := dial(t, repo).CreateBook(as("Bearer admin"), &pb.Book{Title: "Ficciones", Isbn: isbn, Price: 999})
+		return repo, book, err
+	}
+
+	t.Run("HyphenatedISBN", func(t *testing.T) {
+		repo, book, err := create("978-0-306-40615-7")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "9780306406157", book.GetIsbn())
+		assert.EqualValues(t, "9780306406157", repo.saved.ISBN)
+		assert.EqualValues(t, domain.StatusPendingReview, repo.saved.Status)
+	})
+
+	t.Run("ISBN10", func(t *testing.T) {
+		repo, _, err := create("0-306-40615-2")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "9780306406157", repo.saved.ISBN)
+	})
+
+	t.Run("NoISBN", func(t *testing.T) {
+		repo, _, err := create("")
+		assert.Nil(t, err)
+		assert.Empty(t, repo.saved.ISBN)
+	})
+
+	t.Run("InvalidISBN", func(t *testing.T) {
+		repo, _, err := create("978-0-306-40615-8")
+		assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, repo.saved)
+	})
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a span for every request, continuing the trace the
+// caller's traceparent header belongs to. The span is named after the route
+// rather than the path, so /books/1 and /books/2 are grouped.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method + " unmatched"
+		}
+
+		ctx, span := tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("books-api", route, c.Request)...),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// authSpan is where Auth keeps the span of the request being validated
+const authSpan = "tracing_auth_span"
+
+type pendingAuth struct {
+	span   trace.Span
+	parent context.Context
+}
+
+// end closes the validation span and puts the request's own span back as
+// the current one, it's a no-op once the span has ended
+func (p *pendingAuth) end(c *gin.Context) {
+	if p.span == nil {
+		return
+	}
+	p.span.End()
+	p.span = nil
+	c.Request = c.Request.WithContext(p.parent)
+}
+
+// Auth traces the token validation requiresAuth performs before calling h,
+// typically auth.RequiresAuth and its call to the users service. The span
+// ends as soon as h is reached, or when the request is turned down.
+func Auth(h gin.HandlerFunc, requiresAuth func(gin.HandlerFunc) gin.HandlerFunc) gin.HandlerFunc {
+	authenticated := requiresAuth(func(c *gin.Context) {
+		if pending, ok := c.Get(authSpan); ok {
+			pending.(*pendingAuth).end(c)
+		}
+		h(c)
+	})
+
+	return func(c *gin.Context) {
+		parent := c.Request.Context()
+		ctx, span := tracer().Start(parent, "auth.RequiresAuth", trace.WithSpanKind(trace.SpanKindClient))
+		pending := &pendingAuth{span: span, parent: parent}
+		c.Set(authSpan, pending)
+		c.Request = c.Request.WithContext(ctx)
+
+		authenticated(c)
+
+		if pending.span != nil {
+			pending.span.SetStatus(codes.Error, "unauthorized")
+			pending.span.SetAttributes(semconv.HTTPStatusCodeKey.Int(c.Writer.Status()))
+			pending.end(c)
+		}
+	}
+}
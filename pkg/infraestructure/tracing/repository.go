@@ -0,0 +1,240 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FacuBar/bookstore_books-api/pkg/core/domain"
+	"github.com/FacuBar/bookstore_books-api/pkg/core/ports"
+	"github.com/FacuBar/bookstore_utils-go/rest_errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// repository records a span for every call to the repository it wraps
+type repository struct {
+	next ports.BooksRepositoryInterface
+}
+
+// Repository traces br's calls as children of the span of the context they're
+// given, the SQL statements they run become children of theirs
+func Repository(br ports.BooksRepositoryInterface) ports.BooksRepositoryInterface {
+	return &repository{next: br}
+}
+
+func (r *repository) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "repository."+method,
+		trace.WithAttributes(attribute.String("code.function", method)),
+	)
+}
+
+// end closes a repository span, only server errors mark it as failed, a
+// missing record or an invalid one is an expected outcome
+func end(span trace.Span, err rest_errors.RestErr) {
+	if err != nil {
+		span.SetAttributes(attribute.Int("error.status", err.Status()))
+		if err.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, err.Message())
+		}
+	}
+	span.End()
+}
+
+func (r *repository) SaveAuthor(ctx context.Context, actorID int64, author *domain.Author) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "SaveAuthor")
+	defer func() { end(span, err) }()
+	return r.next.SaveAuthor(ctx, actorID, author)
+}
+
+func (r *repository) UpdateAuthor(ctx context.Context, actorID int64, author *domain.Author) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "UpdateAuthor")
+	defer func() { end(span, err) }()
+	return r.next.UpdateAuthor(ctx, actorID, author)
+}
+
+func (r *repository) GetAuthorById(ctx context.Context, id int64) (result *domain.AuthorDenormalized, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetAuthorById")
+	defer func() { end(span, err) }()
+	return r.next.GetAuthorById(ctx, id)
+}
+
+func (r *repository) ListAuthors(ctx context.Context, opts domain.ListOptions) (result []domain.Author, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "ListAuthors")
+	defer func() { end(span, err) }()
+	return r.next.ListAuthors(ctx, opts)
+}
+
+func (r *repository) GetAuthorByName(ctx context.Context, firstName string, lastName string) (result *domain.Author, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetAuthorByName")
+	defer func() { end(span, err) }()
+	return r.next.GetAuthorByName(ctx, firstName, lastName)
+}
+
+func (r *repository) SavePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "SavePublisher")
+	defer func() { end(span, err) }()
+	return r.next.SavePublisher(ctx, actorID, publisher)
+}
+
+func (r *repository) UpdatePublisher(ctx context.Context, actorID int64, publisher *domain.Publisher) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "UpdatePublisher")
+	defer func() { end(span, err) }()
+	return r.next.UpdatePublisher(ctx, actorID, publisher)
+}
+
+func (r *repository) GetPublisherById(ctx context.Context, id int64) (result *domain.PublisherDenormalized, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetPublisherById")
+	defer func() { end(span, err) }()
+	return r.next.GetPublisherById(ctx, id)
+}
+
+func (r *repository) ListPublishers(ctx context.Context, opts domain.ListOptions) (result []domain.Publisher, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "ListPublishers")
+	defer func() { end(span, err) }()
+	return r.next.ListPublishers(ctx, opts)
+}
+
+func (r *repository) GetPublisherByName(ctx context.Context, name string) (result *domain.Publisher, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetPublisherByName")
+	defer func() { end(span, err) }()
+	return r.next.GetPublisherByName(ctx, name)
+}
+
+func (r *repository) SaveBook(ctx context.Context, actorID int64, book *domain.Book) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "SaveBook")
+	defer func() { end(span, err) }()
+	return r.next.SaveBook(ctx, actorID, book)
+}
+
+func (r *repository) SaveBooks(ctx context.Context, actorID int64, books []domain.Book) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "SaveBooks")
+	defer func() { end(span, err) }()
+	return r.next.SaveBooks(ctx, actorID, books)
+}
+
+func (r *repository) SaveImportBatch(ctx context.Context, actorID int64, books []domain.Book, checkpoint domain.ImportCheckpoint) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "SaveImportBatch")
+	defer func() { end(span, err) }()
+	return r.next.SaveImportBatch(ctx, actorID, books, checkpoint)
+}
+
+func (r *repository) GetImportCheckpoint(ctx context.Context, name string) (result int, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetImportCheckpoint")
+	defer func() { end(span, err) }()
+	return r.next.GetImportCheckpoint(ctx, name)
+}
+
+func (r *repository) UpdateBook(ctx context.Context, actorID int64, book *domain.Book) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "UpdateBook")
+	defer func() { end(span, err) }()
+	return r.next.UpdateBook(ctx, actorID, book)
+}
+
+func (r *repository) GetBookById(ctx context.Context, id int64) (result *domain.BookDenormalized, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetBookById")
+	defer func() { end(span, err) }()
+	return r.next.GetBookById(ctx, id)
+}
+
+func (r *repository) GetBookByISBN(ctx context.Context, isbn string) (result *domain.Book, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetBookByISBN")
+	defer func() { end(span, err) }()
+	return r.next.GetBookByISBN(ctx, isbn)
+}
+
+func (r *repository) ListBooks(ctx context.Context, opts domain.ListOptions) (result []domain.Book, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "ListBooks")
+	defer func() { end(span, err) }()
+	return r.next.ListBooks(ctx, opts)
+}
+
+func (r *repository) ListSellerBooks(ctx context.Context, filter domain.SellerBooksFilter, opts domain.ListOptions) (result []domain.Book, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "ListSellerBooks")
+	defer func() { end(span, err) }()
+	return r.next.ListSellerBooks(ctx, filter, opts)
+}
+
+func (r *repository) UpdateBookStatus(ctx context.Context, actorID int64, bookID int64, version int64, from string, to string) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "UpdateBookStatus")
+	defer func() { end(span, err) }()
+	return r.next.UpdateBookStatus(ctx, actorID, bookID, version, from, to)
+}
+
+func (r *repository) GetPublishersByIds(ctx context.Context, ids []int64) (result map[int64]domain.Publisher, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetPublishersByIds")
+	defer func() { end(span, err) }()
+	return r.next.GetPublishersByIds(ctx, ids)
+}
+
+func (r *repository) GetAuthorsByBookIds(ctx context.Context, ids []int64) (result map[int64][]domain.Author, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetAuthorsByBookIds")
+	defer func() { end(span, err) }()
+	return r.next.GetAuthorsByBookIds(ctx, ids)
+}
+
+func (r *repository) GetAuthorsByPublisherIds(ctx context.Context, ids []int64) (result map[int64][]domain.Author, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetAuthorsByPublisherIds")
+	defer func() { end(span, err) }()
+	return r.next.GetAuthorsByPublisherIds(ctx, ids)
+}
+
+func (r *repository) GetBooksByAuthorIds(ctx context.Context, ids []int64) (result map[int64][]domain.Book, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetBooksByAuthorIds")
+	defer func() { end(span, err) }()
+	return r.next.GetBooksByAuthorIds(ctx, ids)
+}
+
+func (r *repository) GetBooksByPublisherIds(ctx context.Context, ids []int64) (result map[int64][]domain.Book, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetBooksByPublisherIds")
+	defer func() { end(span, err) }()
+	return r.next.GetBooksByPublisherIds(ctx, ids)
+}
+
+func (r *repository) GetPendingBooks(ctx context.Context) (result []domain.Submission, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetPendingBooks")
+	defer func() { end(span, err) }()
+	return r.next.GetPendingBooks(ctx)
+}
+
+func (r *repository) ModerateBook(ctx context.Context, moderation *domain.Moderation) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "ModerateBook")
+	defer func() { end(span, err) }()
+	return r.next.ModerateBook(ctx, moderation)
+}
+
+func (r *repository) GetModerations(ctx context.Context, id int64) (result []domain.Moderation, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetModerations")
+	defer func() { end(span, err) }()
+	return r.next.GetModerations(ctx, id)
+}
+
+func (r *repository) UpdateBookPrice(ctx context.Context, actorID int64, bookID int64, version int64, price int64) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "UpdateBookPrice")
+	defer func() { end(span, err) }()
+	return r.next.UpdateBookPrice(ctx, actorID, bookID, version, price)
+}
+
+func (r *repository) GetPriceHistory(ctx context.Context, id int64) (result []domain.PriceChange, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetPriceHistory")
+	defer func() { end(span, err) }()
+	return r.next.GetPriceHistory(ctx, id)
+}
+
+func (r *repository) SavePromotion(ctx context.Context, actorID int64, promotion *domain.Promotion) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "SavePromotion")
+	defer func() { end(span, err) }()
+	return r.next.SavePromotion(ctx, actorID, promotion)
+}
+
+func (r *repository) GetAuditLog(ctx context.Context, filter domain.AuditFilter) (result []domain.AuditEntry, err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "GetAuditLog")
+	defer func() { end(span, err) }()
+	return r.next.GetAuditLog(ctx, filter)
+}
+
+func (r *repository) ExportBooks(ctx context.Context, fn func(domain.CatalogEntry) error) (err rest_errors.RestErr) {
+	ctx, span := r.start(ctx, "ExportBooks")
+	defer func() { end(span, err) }()
+	return r.next.ExportBooks(ctx, fn)
+}
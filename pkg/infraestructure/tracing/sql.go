@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Connector wraps a database connector so every statement run through it gets
+// a span. Spans are named after the statement's leading `-- name` comment, as
+// every query of the repository starts with one.
+func Connector(connector driver.Connector) driver.Connector {
+	return &tracedConnector{Connector: connector}
+}
+
+// statementName returns the name a statement is traced under
+func statementName(query string) string {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "--") {
+		name, rest := query[2:], ""
+		if i := strings.IndexByte(name, '\n'); i >= 0 {
+			name, rest = name[:i], name[i+1:]
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+		query = rest
+	}
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "sql"
+}
+
+// traceStatement records a span for a statement that ran from start until
+// now. Spans are started once the statement is over so the driver.ErrSkip
+// the driver answers with, asking database/sql to prepare the statement
+// instead, doesn't leave a span of its own.
+func traceStatement(ctx context.Context, query string, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	_, span := tracer().Start(ctx, statementName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBStatementKey.String(query),
+		),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type tracedConnector struct {
+	driver.Connector
+}
+
+func (tc *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := tc.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn}, nil
+}
+
+// tracedConn forwards every optional interface database/sql looks for to the
+// wrapped connection, falling back to what database/sql would do without it
+type tracedConn struct {
+	driver.Conn
+}
+
+func (tc *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return tc.PrepareContext(context.Background(), query)
+}
+
+func (tc *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := tc.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = tc.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, query: query}, nil
+}
+
+func (tc *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := tc.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return tc.Conn.Begin()
+}
+
+func (tc *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := tc.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	traceStatement(ctx, query, start, err)
+	return result, err
+}
+
+func (tc *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := tc.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	traceStatement(ctx, query, start, err)
+	return rows, err
+}
+
+func (tc *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := tc.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (tc *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := tc.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (tc *tracedConn) IsValid() bool {
+	if validator, ok := tc.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (tc *tracedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := tc.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type tracedStmt struct {
+	driver.Stmt
+	query string
+}
+
+func (ts *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := ts.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			result, err = ts.Stmt.Exec(values)
+		}
+	}
+	traceStatement(ctx, ts.query, start, err)
+	return result, err
+}
+
+func (ts *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := ts.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = ts.Stmt.Query(values)
+		}
+	}
+	traceStatement(ctx, ts.query, start, err)
+	return rows, err
+}
+
+func (ts *tracedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := ts.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("the driver doesn't support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record installs a provider keeping every span in memory
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestStatementName(t *testing.T) {
+	cases := map[string]string{
+		"-- save book\nINSERT INTO books (title) VALUES (?);": "save book",
+		"\n\t-- get book by id\n\tSELECT * FROM books;":       "get book by id",
+		"--\nSELECT 1":        "SELECT",
+		"select * from books": "SELECT",
+		"   ":                 "sql",
+	}
+	for query, name := range cases {
+		assert.EqualValues(t, name, statementName(query), query)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := record(t)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/books/:book_id", func(c *gin.Context) {
+		_, span := tracer().Start(c.Request.Context(), "get book")
+		span.End()
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/books/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	assert.EqualValues(t, "GET /books/:book_id", server.Name())
+	assert.EqualValues(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.EqualValues(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.EqualValues(t, codes.Error, server.Status().Code)
+
+	assert.EqualValues(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// stands in for auth.RequiresAuth, only letting requests with a token through
+	requiresAuth := func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if c.GetHeader("Authorization") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			next(c)
+		}
+	}
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.POST("/books", Auth(func(c *gin.Context) {
+		_, span := tracer().Start(c.Request.Context(), "create book")
+		span.End()
+		c.Status(http.StatusCreated)
+	}, requiresAuth))
+
+	t.Run("Authorized", func(t *testing.T) {
+		recorder := record(t)
+		router.ServeHTTP(httptest.NewRecorder(), func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/books", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			return req
+		}())
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 3)
+		validation, handler, server := spans[0], spans[1], spans[2]
+
+		assert.EqualValues(t, "auth.RequiresAuth", validation.Name())
+		assert.EqualValues(t, codes.Unset, validation.Status().Code)
+		assert.EqualValues(t, server.SpanContext().SpanID(), validation.Parent().SpanID())
+		// the handler runs once the token is validated, under the request's span
+		assert.EqualValues(t, server.SpanContext().SpanID(), handler.Parent().SpanID())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		recorder := record(t)
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/books", nil))
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 2)
+		assert.EqualValues(t, "auth.RequiresAuth", spans[0].Name())
+		assert.EqualValues(t, codes.Error, spans[0].Status().Code)
+	})
+}